@@ -0,0 +1,106 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package balancer provides the strategies which select a cluster member to
+// serve the requests of a session, the selected member will be bound to the
+// session router until the member leaves the cluster.
+package balancer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/session"
+)
+
+// Names of the built-in balancers
+const (
+	Random         = "random"
+	RoundRobin     = "round-robin"
+	LeastConn      = "least-conn"
+	ConsistentHash = "consistent-hash"
+	LabelAffinity  = "label-affinity"
+)
+
+type (
+	// Balancer selects a member from the members which provide the same
+	// service, the session will be nil if the request is not issued by a
+	// session, e.g: Node.Call
+	Balancer interface {
+		Select(s *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo
+	}
+
+	// Tracker is implemented by the balancers which are interested in the
+	// sessions bound to members
+	Tracker interface {
+		// Bind is called after the session bound to the member address
+		Bind(s *session.Session, addr string)
+		// Unbind is called after the session closed or the member removed
+		Unbind(s *session.Session)
+	}
+
+	// Factory creates a balancer instance, every service holds its own
+	// instance in each node
+	Factory func() Balancer
+)
+
+// ErrUnknownBalancer is returned by New if the name is not registered
+var ErrUnknownBalancer = errors.New("balancer: unknown balancer")
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+func init() {
+	Register(Random, NewRandom)
+	Register(RoundRobin, NewRoundRobin)
+	Register(LeastConn, NewLeastConn)
+	Register(ConsistentHash, NewConsistentHash)
+	Register(LabelAffinity, NewLabelAffinity)
+}
+
+// Register registers a balancer factory with the name, the name can be used
+// in component.WithBalancer. It panics if the name has been registered
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, found := factories[name]; found {
+		panic(fmt.Sprintf("balancer: %s has registered", name))
+	}
+	factories[name] = factory
+}
+
+// New creates a balancer by the registered name, the random balancer will be
+// returned if the name is empty
+func New(name string) (Balancer, error) {
+	if name == "" {
+		return NewRandom(), nil
+	}
+	mu.RLock()
+	factory, found := factories[name]
+	mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBalancer, name)
+	}
+	return factory(), nil
+}
@@ -0,0 +1,105 @@
+package balancer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/session"
+)
+
+var members = []*clusterpb.MemberInfo{
+	{Label: "eu", ServiceAddr: "127.0.0.1:4450"},
+	{Label: "us", ServiceAddr: "127.0.0.1:4451"},
+	{Label: "us", ServiceAddr: "127.0.0.1:4452"},
+}
+
+func TestNew(t *testing.T) {
+	if b, err := New(""); err != nil {
+		t.Fatal(err)
+	} else if _, ok := b.(randomBalancer); !ok {
+		t.Fail()
+	}
+	if b, err := New(RoundRobin); err != nil {
+		t.Fatal(err)
+	} else if _, ok := b.(*roundRobinBalancer); !ok {
+		t.Fail()
+	}
+	if b, err := New(LeastConn); err != nil {
+		t.Fatal(err)
+	} else if _, ok := b.(Tracker); !ok {
+		t.Fail()
+	}
+	if _, err := New("unknown"); !errors.Is(err, ErrUnknownBalancer) {
+		t.Fatalf("expect %v, got %v", ErrUnknownBalancer, err)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	b := NewRoundRobin()
+	for i := 0; i < 6; i++ {
+		if m := b.Select(nil, members); m != members[i%len(members)] {
+			t.Fatalf("unexpected member %s", m.ServiceAddr)
+		}
+	}
+}
+
+func TestLeastConn(t *testing.T) {
+	b := NewLeastConn()
+	tracker := b.(Tracker)
+	sessions := []*session.Session{session.New(nil), session.New(nil), session.New(nil)}
+	for i, s := range sessions {
+		m := b.Select(s, members)
+		if m != members[i] {
+			t.Fatalf("unexpected member %s", m.ServiceAddr)
+		}
+		tracker.Bind(s, m.ServiceAddr)
+	}
+
+	tracker.Unbind(sessions[1])
+	if m := b.Select(nil, members); m != members[1] {
+		t.Fatalf("unexpected member %s", m.ServiceAddr)
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	b := NewConsistentHash()
+	s := session.New(nil)
+	s.Bind(10086)
+	selected := b.Select(s, members)
+	for i := 0; i < 10; i++ {
+		if m := b.Select(s, members); m != selected {
+			t.Fatalf("unexpected member %s", m.ServiceAddr)
+		}
+	}
+
+	// Only the users of the removed member will be moved
+	var remain []*clusterpb.MemberInfo
+	for _, m := range members {
+		if m != selected {
+			remain = append(remain, m)
+		}
+	}
+	for uid := int64(1); uid < 100; uid++ {
+		s.Bind(uid)
+		if m := b.Select(s, members); m != selected && b.Select(s, remain) != m {
+			t.Fatalf("user %d moved from %s", uid, m.ServiceAddr)
+		}
+	}
+}
+
+func TestLabelAffinity(t *testing.T) {
+	b := NewLabelAffinity()
+	s := session.New(nil)
+	s.Set(LabelKey, "us")
+	for i := 0; i < 4; i++ {
+		if m := b.Select(s, members); m.Label != "us" {
+			t.Fatalf("unexpected member %s", m.ServiceAddr)
+		}
+	}
+
+	s.Set(LabelKey, "asia")
+	if m := b.Select(s, members); m == nil {
+		t.Fail()
+	}
+}
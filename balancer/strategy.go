@@ -0,0 +1,179 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package balancer
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/session"
+)
+
+// LabelKey is the session data key of the label which label affinity balancer
+// prefers
+const LabelKey = "balancer.label"
+
+type (
+	randomBalancer struct{}
+
+	roundRobinBalancer struct {
+		next uint64
+	}
+
+	leastConnBalancer struct {
+		sync.Mutex
+		conns    map[string]int   // member address => bound sessions count
+		sessions map[int64]string // session id => member address
+	}
+
+	consistentHashBalancer struct{}
+
+	labelAffinityBalancer struct {
+		roundRobinBalancer
+	}
+)
+
+// NewRandom returns a balancer which selects member randomly
+func NewRandom() Balancer {
+	return randomBalancer{}
+}
+
+func (randomBalancer) Select(_ *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	return members[rand.Intn(len(members))]
+}
+
+// NewRoundRobin returns a balancer which selects member in turn
+func NewRoundRobin() Balancer {
+	return &roundRobinBalancer{}
+}
+
+func (b *roundRobinBalancer) Select(_ *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	n := atomic.AddUint64(&b.next, 1) - 1
+	return members[n%uint64(len(members))]
+}
+
+// NewLeastConn returns a balancer which selects the member bound by the least
+// sessions
+func NewLeastConn() Balancer {
+	return &leastConnBalancer{
+		conns:    map[string]int{},
+		sessions: map[int64]string{},
+	}
+}
+
+func (b *leastConnBalancer) Select(_ *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	b.Lock()
+	defer b.Unlock()
+	selected := members[0]
+	for _, m := range members[1:] {
+		if b.conns[m.ServiceAddr] < b.conns[selected.ServiceAddr] {
+			selected = m
+		}
+	}
+	return selected
+}
+
+func (b *leastConnBalancer) Bind(s *session.Session, addr string) {
+	b.Lock()
+	defer b.Unlock()
+	if old, found := b.sessions[s.ID()]; found {
+		b.release(old)
+	}
+	b.sessions[s.ID()] = addr
+	b.conns[addr]++
+}
+
+func (b *leastConnBalancer) Unbind(s *session.Session) {
+	b.Lock()
+	defer b.Unlock()
+	if addr, found := b.sessions[s.ID()]; found {
+		delete(b.sessions, s.ID())
+		b.release(addr)
+	}
+}
+
+// release decreases the sessions count of member, must be called with lock held
+func (b *leastConnBalancer) release(addr string) {
+	if b.conns[addr] <= 1 {
+		delete(b.conns, addr)
+	} else {
+		b.conns[addr]--
+	}
+}
+
+// NewConsistentHash returns a balancer which selects member by the UID of
+// session with rendezvous hashing, so the same user is always served by the
+// same member, and only the users of a leaving member will be moved. The
+// session id is used if the session has not bound a UID
+func NewConsistentHash() Balancer {
+	return consistentHashBalancer{}
+}
+
+func (consistentHashBalancer) Select(s *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	if s == nil {
+		return members[rand.Intn(len(members))]
+	}
+	key := s.UID()
+	if key == 0 {
+		key = s.ID()
+	}
+	suffix := strconv.FormatInt(key, 10)
+
+	var selected *clusterpb.MemberInfo
+	var max uint64
+	for _, m := range members {
+		h := fnv.New64a()
+		h.Write([]byte(m.ServiceAddr))
+		h.Write([]byte(suffix))
+		if score := h.Sum64(); selected == nil || score > max {
+			selected, max = m, score
+		}
+	}
+	return selected
+}
+
+// NewLabelAffinity returns a balancer which prefers the members whose label
+// equals the session value of LabelKey, and selects in turn from all members
+// if none of them matches
+func NewLabelAffinity() Balancer {
+	return &labelAffinityBalancer{}
+}
+
+func (b *labelAffinityBalancer) Select(s *session.Session, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	if s != nil {
+		if label := s.String(LabelKey); label != "" {
+			var matched []*clusterpb.MemberInfo
+			for _, m := range members {
+				if m.Label == label {
+					matched = append(matched, m)
+				}
+			}
+			if len(matched) > 0 {
+				members = matched
+			}
+		}
+	}
+	return b.roundRobinBalancer.Select(s, members)
+}
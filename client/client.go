@@ -0,0 +1,750 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package client implements the amoeba client which connects to the server
+// over TCP or WebSocket, it is mainly used by the bots and load tests.
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/revzim/amoeba/crypt"
+	amoebaerrors "github.com/revzim/amoeba/errors"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/session"
+)
+
+const (
+	clientWriteBacklog = 64
+	protocolVersion    = 1
+	handshakeOK        = 200
+	handshakeRejected  = 403
+	handshakeOutdated  = 501
+	nonceSize          = 16
+)
+
+// Errors that could be occurred in client
+var (
+	ErrClosed           = errors.New("client closed")
+	ErrInvalidCallback  = errors.New("callback should be func([]byte) or func(*T)")
+	ErrHandshakeTimeout = errors.New("handshake timeout")
+	ErrNotSupported     = errors.New("operation not supported by client")
+	ErrOutdated         = errors.New("client version outdated")
+	ErrRejected         = errors.New("handshake rejected by server")
+	ErrKeyExchange      = errors.New("key exchange refused by server")
+	ErrInvalidNonce     = errors.New("invalid handshake nonce")
+)
+
+type (
+	// Client is the amoeba client, the callbacks are invoked in the read
+	// goroutine of client in order of the messages received
+	Client struct {
+		opts        options
+		conn        net.Conn
+		decoder     *codec.Decoder
+		session     *session.Session
+		dict        *message.Dictionary // routes dictionary received from server
+		compression *codec.Compression  // negotiated compression, nil if disabled
+		keyPair     *crypt.KeyPair      // key pair of key exchange, nil if disabled
+		nonce       []byte              // random value sent in handshake
+		mid         uint64              // last message id
+		lastAt      int64               // last packet received unix time stamp
+		chSend      chan []byte         // send queue
+		chDie       chan struct{}       // close channel
+		once        sync.Once
+
+		heartbeat  time.Duration // heartbeat interval received from server
+		token      string        // resume token received from server
+		resumed    bool          // whether the previous session is resumed
+		chReady    chan error    // handshake result
+		handshaked bool          // handshake packet received, read goroutine only
+
+		capabilities  []string // capabilities supported by both client and server
+		handshakeData []byte   // user data of handshake response
+
+		muErr sync.Mutex
+		err   error // the reason why client closed
+
+		// push handlers
+		muEvents sync.RWMutex
+		events   map[string]reflect.Value
+
+		// response handlers
+		muResponses sync.Mutex
+		responses   map[uint64]reflect.Value
+	}
+
+	// KickError represents the client has been kicked by server, the reason is
+	// sent by server before the connection closed
+	KickError struct {
+		Reason string `json:"reason"`
+	}
+
+	// handshakeResponse represents the handshake data sent by server
+	handshakeResponse struct {
+		Code int             `json:"code"`
+		User json.RawMessage `json:"user"`
+		Sys  struct {
+			Protocol     int      `json:"protocol"`
+			Capabilities []string `json:"capabilities"`
+
+			Heartbeat float64           `json:"heartbeat"`
+			Dict      map[string]uint16 `json:"dict"`
+			DictHash  string            `json:"dictHash"`
+			Resume    string            `json:"resume"`
+			Resumed   bool              `json:"resumed"`
+
+			Compress          string `json:"compress"`
+			CompressThreshold int    `json:"compressThreshold"`
+			CompressDict      bool   `json:"compressDict"`
+
+			KeyExchange string `json:"keyExchange"`
+			PublicKey   string `json:"publicKey"`
+			Nonce       string `json:"nonce"`
+		} `json:"sys"`
+	}
+
+	// handshakeRequest represents the handshake data sent to server
+	handshakeRequest struct {
+		Sys  map[string]interface{} `json:"sys"`
+		User interface{}            `json:"user,omitempty"`
+	}
+
+	// response holds the response of blocking call
+	response struct {
+		data []byte
+		err  *amoebaerrors.Error // the request failed if not nil
+	}
+)
+
+var typeOfBytes = reflect.TypeOf([]byte(nil))
+
+// Dial connects to the server and returns after the handshake completed, the
+// address with ws:// or wss:// scheme will be connected over WebSocket
+func Dial(addr string, opts ...Option) (*Client, error) {
+	c := &Client{
+		opts:      defaultOptions(),
+		decoder:   codec.NewDecoder(),
+		lastAt:    time.Now().Unix(),
+		chSend:    make(chan []byte, clientWriteBacklog),
+		chDie:     make(chan struct{}),
+		chReady:   make(chan error, 1),
+		events:    map[string]reflect.Value{},
+		responses: map[uint64]reflect.Value{},
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	for route, callback := range c.opts.handlers {
+		if err := c.On(route, callback); err != nil {
+			return nil, err
+		}
+	}
+	c.dict = message.NewDictionary(c.opts.dict)
+	c.session = session.New(&entity{client: c})
+
+	conn, err := dial(addr, c.opts.timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+
+	sys := map[string]interface{}{
+		"protocol":     protocolVersion,
+		"type":         c.opts.clientType,
+		"version":      c.opts.version,
+		"capabilities": c.opts.capabilities,
+	}
+	if c.opts.resume != "" {
+		sys["resume"] = c.opts.resume
+	}
+	if len(c.opts.dict) > 0 {
+		sys["dictHash"] = message.DictionaryHash(c.opts.dict)
+	}
+	if len(c.opts.compress) > 0 {
+		sys["compress"] = c.opts.compress
+		// the shared dictionary is only supported by deflate
+		checksum := codec.DictChecksum(c.opts.compressDict)
+		for _, algo := range c.opts.compress {
+			if algo == Deflate && checksum != "" {
+				sys["compressDict"] = checksum
+				break
+			}
+		}
+	}
+	c.nonce = make([]byte, nonceSize)
+	if _, err := rand.Read(c.nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	sys["nonce"] = base64.StdEncoding.EncodeToString(c.nonce)
+	if c.opts.keyExchange {
+		kp, err := crypt.GenerateKeyPair()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		c.keyPair = kp
+		sys["keyExchange"] = crypt.X25519
+		sys["publicKey"] = base64.StdEncoding.EncodeToString(kp.Public)
+	}
+	data, err := json.Marshal(handshakeRequest{Sys: sys, User: c.opts.userData})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p, err := codec.Encode(packet.Handshake, data)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(p); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go c.read()
+
+	timer := time.NewTimer(c.opts.timeout)
+	defer timer.Stop()
+	select {
+	case err = <-c.chReady:
+	case <-timer.C:
+		err = ErrHandshakeTimeout
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	go c.write()
+	return c, nil
+}
+
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	if !strings.HasPrefix(addr, "ws://") && !strings.HasPrefix(addr, "wss://") {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = timeout
+	conn, _, err := dialer.Dial(addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newWSConn(conn), nil
+}
+
+// Session returns the session of client, which is passed to the pipeline
+// functions and could be used to store the connection related data
+func (c *Client) Session() *session.Session {
+	return c.session
+}
+
+// ResumeToken returns the token which could be used to resume the session after
+// the connection broken, empty if the server does not enable session resume
+func (c *Client) ResumeToken() string {
+	return c.token
+}
+
+// Resumed reports whether the client has been re-attached to the previous
+// session by the resume token
+func (c *Client) Resumed() bool {
+	return c.resumed
+}
+
+// Capabilities returns the capabilities supported by both client and server
+func (c *Client) Capabilities() []string {
+	return c.capabilities
+}
+
+// HandshakeData returns the user data of handshake response in JSON, nil if
+// the server does not send it
+func (c *Client) HandshakeData() []byte {
+	return c.handshakeData
+}
+
+// Dictionary returns a copy of the routes dictionary used by client, it could be
+// cached and passed to WithDictionary, so the server will not send the dictionary
+// again in handshake if it is unchanged
+func (c *Client) Dictionary() map[string]uint16 {
+	return c.dict.Routes()
+}
+
+// Compression returns the compression algorithm negotiated with server, empty
+// if the packets are not compressed
+func (c *Client) Compression() string {
+	if c.compression == nil {
+		return ""
+	}
+	return c.compression.Algorithm()
+}
+
+// Request sends a request to server, the callback will be invoked with the
+// response, it should be func([]byte) or func(*T) which the response will be
+// unmarshaled into T by the serializer of client
+func (c *Client) Request(route string, v interface{}, callback interface{}) error {
+	cb, err := callbackOf(callback)
+	if err != nil {
+		return err
+	}
+
+	mid := atomic.AddUint64(&c.mid, 1)
+	c.muResponses.Lock()
+	c.responses[mid] = cb
+	c.muResponses.Unlock()
+
+	err = c.send(&message.Message{Type: message.Request, Route: route, ID: mid}, v)
+	if err != nil {
+		c.muResponses.Lock()
+		delete(c.responses, mid)
+		c.muResponses.Unlock()
+	}
+	return err
+}
+
+// Call sends a request to server and waits for the response, the response
+// will be unmarshaled into reply. The timeout of client will be applied if
+// ctx does not carry a deadline, and an *errors.Error will be returned if the
+// request failed on server
+func (c *Client) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+		defer cancel()
+	}
+
+	ch := make(chan response, 1)
+	mid := atomic.AddUint64(&c.mid, 1)
+	c.muResponses.Lock()
+	c.responses[mid] = reflect.ValueOf(ch)
+	c.muResponses.Unlock()
+	defer func() {
+		c.muResponses.Lock()
+		delete(c.responses, mid)
+		c.muResponses.Unlock()
+	}()
+
+	err := c.send(&message.Message{Type: message.Request, Route: route, ID: mid}, v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			return r.err
+		}
+		return c.unmarshal(r.data, reply)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.chDie:
+		return ErrClosed
+	}
+}
+
+// Notify sends a notification to server
+func (c *Client) Notify(route string, v interface{}) error {
+	return c.send(&message.Message{Type: message.Notify, Route: route}, v)
+}
+
+// On registers the callback for the pushes of route, the callback should be
+// func([]byte) or func(*T) which the push will be unmarshaled into T
+func (c *Client) On(route string, callback interface{}) error {
+	cb, err := callbackOf(callback)
+	if err != nil {
+		return err
+	}
+	c.muEvents.Lock()
+	c.events[route] = cb
+	c.muEvents.Unlock()
+	return nil
+}
+
+// Close closes the client and the low-level connection
+func (c *Client) Close() error {
+	err := ErrClosed
+	c.once.Do(func() {
+		close(c.chDie)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// Closed returns a channel which will be closed when the client closed
+func (c *Client) Closed() <-chan struct{} {
+	return c.chDie
+}
+
+// Err returns the reason why the client closed, a *KickError will be returned
+// if the client has been kicked by server, nil if the client is working or
+// closed by Close
+func (c *Client) Err() error {
+	c.muErr.Lock()
+	defer c.muErr.Unlock()
+	return c.err
+}
+
+func (c *Client) fail(err error) {
+	c.muErr.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.muErr.Unlock()
+}
+
+func (e *KickError) Error() string {
+	return fmt.Sprintf("kicked by server: %s", e.Reason)
+}
+
+func callbackOf(callback interface{}) (reflect.Value, error) {
+	cb := reflect.ValueOf(callback)
+	if cb.Kind() != reflect.Func {
+		return cb, ErrInvalidCallback
+	}
+	typ := cb.Type()
+	if typ.NumIn() != 1 || typ.NumOut() != 0 {
+		return cb, ErrInvalidCallback
+	}
+	if in := typ.In(0); in != typeOfBytes && in.Kind() != reflect.Ptr {
+		return cb, ErrInvalidCallback
+	}
+	return cb, nil
+}
+
+func (c *Client) invoke(cb reflect.Value, data []byte) {
+	in := cb.Type().In(0)
+	if in == typeOfBytes {
+		cb.Call([]reflect.Value{reflect.ValueOf(data)})
+		return
+	}
+	v := reflect.New(in.Elem())
+	if err := c.unmarshal(data, v.Interface()); err != nil {
+		log.Println("Unmarshal message failed", err)
+		return
+	}
+	cb.Call([]reflect.Value{v})
+}
+
+func (c *Client) marshal(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if data, ok := v.([]byte); ok {
+		return data, nil
+	}
+	return c.opts.serializer.Marshal(v)
+}
+
+func (c *Client) unmarshal(data []byte, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	if raw, ok := v.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	return c.opts.serializer.Unmarshal(data, v)
+}
+
+func (c *Client) send(m *message.Message, v interface{}) error {
+	data, err := c.marshal(v)
+	if err != nil {
+		return err
+	}
+	m.Data = data
+
+	if pipe := c.opts.pipeline; pipe != nil {
+		if err := pipe.Outbound().Process(c.session, m); err != nil {
+			return err
+		}
+	}
+
+	em, err := c.dict.Encode(m)
+	if err != nil {
+		return err
+	}
+	p, err := c.compression.Encode(packet.Data, em)
+	if err != nil {
+		return err
+	}
+	return c.sendPacket(p)
+}
+
+func (c *Client) sendPacket(p []byte) error {
+	select {
+	case <-c.chDie:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case <-c.chDie:
+		return ErrClosed
+	case c.chSend <- p:
+		return nil
+	}
+}
+
+func (c *Client) write() {
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+	hbd, err := codec.Encode(packet.Heartbeat, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			deadline := time.Now().Add(-2 * c.heartbeat).Unix()
+			if atomic.LoadInt64(&c.lastAt) < deadline {
+				log.Println("Client heartbeat timeout", c.conn.RemoteAddr())
+				c.Close()
+				return
+			}
+			if _, err := c.conn.Write(hbd); err != nil {
+				log.Println(err.Error())
+				c.Close()
+				return
+			}
+
+		case p := <-c.chSend:
+			if _, err := c.conn.Write(p); err != nil {
+				log.Println(err.Error())
+				c.Close()
+				return
+			}
+
+		case <-c.chDie:
+			return
+		}
+	}
+}
+
+func (c *Client) read() {
+	defer c.Close()
+
+	buf := make([]byte, 2048)
+	var deadline time.Time
+	for {
+		// the connection times out if the reassembling packet is not completed
+		if d := c.decoder.Deadline(); !d.Equal(deadline) {
+			if err := c.conn.SetReadDeadline(d); err != nil {
+				c.fail(err)
+				return
+			}
+			deadline = d
+		}
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			select {
+			case <-c.chDie:
+			default:
+				c.fail(err)
+				log.Println(err.Error())
+			}
+			return
+		}
+
+		packets, err := c.decoder.Decode(buf[:n])
+		for _, p := range packets {
+			if err := c.processPacket(p); err != nil {
+				c.fail(err)
+				log.Println(err.Error())
+				return
+			}
+		}
+		if err != nil {
+			c.fail(err)
+			log.Println(err.Error())
+			return
+		}
+	}
+}
+
+func (c *Client) processPacket(p *packet.Packet) error {
+	atomic.StoreInt64(&c.lastAt, time.Now().Unix())
+
+	switch p.Type {
+	case packet.Handshake:
+		// the handshake is completed only once, the unexpected handshake
+		// packets are ignored rather than blocking the read goroutine
+		if c.handshaked {
+			log.Println("Unexpected handshake packet ignored")
+			return nil
+		}
+		c.handshaked = true
+		err := c.handshake(p.Data)
+		c.chReady <- err
+		return err
+
+	case packet.Data:
+		if err := c.compression.Decompress(p); err != nil {
+			return err
+		}
+		msg, err := c.dict.Decode(p.Data)
+		if err != nil {
+			return err
+		}
+		if pipe := c.opts.pipeline; pipe != nil {
+			if err := pipe.Inbound().Process(c.session, msg); err != nil {
+				return err
+			}
+		}
+		c.processMessage(msg)
+
+	case packet.Kick:
+		kick := &KickError{}
+		if len(p.Data) > 0 {
+			if err := json.Unmarshal(p.Data, kick); err != nil {
+				return err
+			}
+		}
+		return kick
+
+	case packet.Heartbeat:
+		// expected
+	}
+	return nil
+}
+
+func (c *Client) handshake(data []byte) error {
+	res := handshakeResponse{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	switch res.Code {
+	case handshakeOutdated:
+		return ErrOutdated
+	case handshakeRejected:
+		return ErrRejected
+	}
+	if res.Code != handshakeOK {
+		return fmt.Errorf("handshake refused by server, code=%d", res.Code)
+	}
+
+	c.capabilities = res.Sys.Capabilities
+	c.handshakeData = res.User
+	c.token = res.Sys.Resume
+	c.resumed = res.Sys.Resumed
+	c.heartbeat = time.Duration(res.Sys.Heartbeat * float64(time.Second))
+	if c.heartbeat <= 0 {
+		c.heartbeat = defaultHeartbeat
+	}
+	// the dictionary of server replaces the stale cached one
+	if len(res.Sys.Dict) > 0 {
+		c.dict = message.NewDictionary(res.Sys.Dict)
+	}
+
+	if res.Sys.Compress != "" {
+		var dict []byte
+		if res.Sys.CompressDict {
+			dict = c.opts.compressDict
+		}
+		compression, err := codec.NewCompression(res.Sys.Compress, res.Sys.CompressThreshold, dict, c.decoder.MaxReassemblySize)
+		if err != nil {
+			return err
+		}
+		c.compression = compression
+	}
+
+	if c.keyPair != nil {
+		if res.Sys.KeyExchange != crypt.X25519 {
+			return ErrKeyExchange
+		}
+		public, err := base64.StdEncoding.DecodeString(res.Sys.PublicKey)
+		if err != nil {
+			return crypt.ErrInvalidPublicKey
+		}
+		cipher, err := c.keyPair.ClientCipher(public)
+		if err != nil {
+			return err
+		}
+		c.session.SetCipher(cipher)
+	}
+	// the nonce of connection is unknown to the server without nonce support
+	if res.Sys.Nonce != "" {
+		nonce, err := base64.StdEncoding.DecodeString(res.Sys.Nonce)
+		if err != nil {
+			return ErrInvalidNonce
+		}
+		c.session.SetNonce(append(append([]byte{}, c.nonce...), nonce...))
+	}
+
+	p, err := codec.Encode(packet.HandshakeAck, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(p)
+	return err
+}
+
+func (c *Client) processMessage(msg *message.Message) {
+	switch msg.Type {
+	case message.Push:
+		c.muEvents.RLock()
+		cb, found := c.events[msg.Route]
+		c.muEvents.RUnlock()
+		if !found {
+			log.Println("Event handler not found", msg.Route)
+			return
+		}
+		c.invoke(cb, msg.Data)
+
+	case message.Response:
+		c.muResponses.Lock()
+		cb, found := c.responses[msg.ID]
+		delete(c.responses, msg.ID)
+		c.muResponses.Unlock()
+		if !found {
+			log.Println("Response handler not found", msg.ID)
+			return
+		}
+
+		var err *amoebaerrors.Error
+		if msg.Error {
+			err = amoebaerrors.Decode(msg.Data)
+		}
+		if cb.Kind() == reflect.Chan {
+			cb.Send(reflect.ValueOf(response{data: append([]byte(nil), msg.Data...), err: err}))
+			return
+		}
+		if err != nil {
+			log.Printf("Request(id: %d) failed: %v", msg.ID, err)
+			return
+		}
+		c.invoke(cb, msg.Data)
+	}
+}
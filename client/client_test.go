@@ -0,0 +1,233 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/scheduler"
+	"github.com/revzim/amoeba/session"
+)
+
+type EchoComponent struct{ component.Base }
+
+func (c *EchoComponent) Echo(s *session.Session, ping *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: ping.Content})
+}
+
+func (c *EchoComponent) Notify(s *session.Session, ping *testdata.Ping) error {
+	return s.Push("EchoComponent.Pushed", &testdata.Pong{Content: ping.Content})
+}
+
+// reverse is a symmetric pipeline function which is used on both ends
+func reverse(_ *session.Session, msg *pipeline.Message) error {
+	for i, j := 0, len(msg.Data)-1; i < j; i, j = i+1, j-1 {
+		msg.Data[i], msg.Data[j] = msg.Data[j], msg.Data[i]
+	}
+	return nil
+}
+
+func newPipeline() pipeline.Pipeline {
+	pip := pipeline.New()
+	pip.Inbound().PushBack(reverse)
+	pip.Outbound().PushBack(reverse)
+	return pip
+}
+
+func startup(t *testing.T, serviceAddr, clientAddr string, ws bool) *cluster.Node {
+	comps := &component.Components{}
+	comps.Register(&EchoComponent{})
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			Pipeline:    newPipeline(),
+			ClientAddr:  clientAddr,
+			IsWebsocket: ws,
+
+			Compression:       []string{cluster.CompressDeflate, cluster.CompressZstd},
+			CompressThreshold: 64,
+			CompressDict:      []byte("amoeba"),
+		},
+		ServiceAddr: serviceAddr,
+	}
+	if err := node.Startup(); err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func dial(t *testing.T, addr string, opts ...client.Option) *client.Client {
+	// the client listener is started asynchronously
+	var err error
+	for i := 0; i < 50; i++ {
+		var c *client.Client
+		if c, err = client.Dial(addr, opts...); err == nil {
+			return c
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal(err)
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	go scheduler.Sched()
+	defer scheduler.Close()
+
+	chUser := make(chan string, 2)
+	env.HandshakeValidator = func(data []byte) error {
+		req := struct {
+			User struct{ Name string } `json:"user"`
+		}{}
+		if err := json.Unmarshal(data, &req); err != nil {
+			return err
+		}
+		chUser <- req.User.Name
+		return nil
+	}
+	defer func() { env.HandshakeValidator = func(_ []byte) error { return nil } }()
+	message.SetDictionary(map[string]uint16{"EchoComponent.Pushed": 1})
+
+	tcp := startup(t, "127.0.0.1:20550", "127.0.0.1:20551", false)
+	defer tcp.Shutdown()
+	ws := startup(t, "127.0.0.1:20552", "127.0.0.1:20553", true)
+	defer ws.Shutdown()
+
+	cases := []struct {
+		addr     string
+		compress string
+	}{
+		{addr: "127.0.0.1:20551", compress: client.Zstd},
+		{addr: "ws://127.0.0.1:20553/", compress: client.Deflate},
+	}
+	for _, cs := range cases {
+		c := dial(t, cs.addr,
+			client.WithUserData(map[string]string{"name": "bot"}),
+			client.WithPipeline(newPipeline()),
+			client.WithCompression(cs.compress, client.Deflate),
+			client.WithCompressionDict([]byte("amoeba")))
+		if name := <-chUser; name != "bot" {
+			t.Fatalf("unexpected user data %q", name)
+		}
+		if c.Compression() != cs.compress {
+			t.Fatalf("unexpected compression %q", c.Compression())
+		}
+
+		chResult := make(chan string, 1)
+		err := c.Request("EchoComponent.Echo", &testdata.Ping{Content: "request"}, func(pong *testdata.Pong) {
+			chResult <- pong.Content
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := <-chResult; res != "request" {
+			t.Fatalf("unexpected response %q", res)
+		}
+
+		pong := &testdata.Pong{}
+		err = c.Call(context.Background(), "EchoComponent.Echo", &testdata.Ping{Content: "call"}, pong)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pong.Content != "call" {
+			t.Fatalf("unexpected reply %q", pong.Content)
+		}
+
+		// large payloads are compressed in both directions
+		snapshot := strings.Repeat("amoeba", 100)
+		err = c.Call(context.Background(), "EchoComponent.Echo", &testdata.Ping{Content: snapshot}, pong)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pong.Content != snapshot {
+			t.Fatalf("unexpected reply %q", pong.Content)
+		}
+
+		// payloads larger than max packet size are fragmented
+		replay := make([]byte, 100*1024)
+		for i := range replay {
+			replay[i] = byte('a' + i*7919%26)
+		}
+		err = c.Call(context.Background(), "EchoComponent.Echo", &testdata.Ping{Content: string(replay)}, pong)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pong.Content != string(replay) {
+			t.Fatal("unexpected fragmented reply")
+		}
+
+		// the route of push is compressed with the dictionary received in handshake
+		if err := c.On("EchoComponent.Pushed", func(pong *testdata.Pong) {
+			chResult <- pong.Content
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Notify("EchoComponent.Notify", &testdata.Ping{Content: "notify"}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case res := <-chResult:
+			if res != "notify" {
+				t.Fatalf("unexpected push %q", res)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("push timeout")
+		}
+
+		if err := c.On("EchoComponent.Pushed", "invalid"); err != client.ErrInvalidCallback {
+			t.Fatalf("unexpected error %v", err)
+		}
+		c.Close()
+		if err := c.Notify("EchoComponent.Notify", &testdata.Ping{}); err != client.ErrClosed {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestDuplicateHandshake(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:20560")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		if _, err := conn.Read(buf); err != nil {
+			return
+		}
+		p, _ := codec.Encode(packet.Handshake, []byte(`{"code":200,"sys":{"heartbeat":30}}`))
+		for i := 0; i < 3; i++ {
+			conn.Write(p)
+		}
+	}()
+
+	c, err := client.Dial("127.0.0.1:20560")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	select {
+	case <-c.Closed():
+	case <-time.After(time.Second):
+		t.Fatal("read goroutine blocked by duplicate handshakes")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsConn is an adapter to net.Conn based on *websocket.Conn
+type wsConn struct {
+	*websocket.Conn
+	reader io.Reader
+}
+
+func newWSConn(conn *websocket.Conn) *wsConn {
+	return &wsConn{Conn: conn}
+}
+
+// Read reads data from the current websocket message, the next message will
+// be read after the current one consumed
+func (c *wsConn) Read(b []byte) (int, error) {
+	for {
+		if c.reader == nil {
+			_, r, err := c.Conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			c.reader = r
+		}
+		n, err := c.reader.Read(b)
+		if err == io.EOF {
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Write writes data as a binary message
+func (c *wsConn) Write(b []byte) (int, error) {
+	if err := c.Conn.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// SetDeadline sets the read and write deadlines of connection
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.Conn.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// entity implements the session.NetworkEntity interface for the session of
+// client, which is used by the pipeline functions
+type entity struct {
+	client *Client
+}
+
+func (e *entity) Push(route string, v interface{}) error { return ErrNotSupported }
+func (e *entity) RPC(route string, v interface{}) error  { return e.client.Notify(route, v) }
+func (e *entity) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	return e.client.Call(ctx, route, v, reply)
+}
+func (e *entity) LastMid() uint64                             { return 0 }
+func (e *entity) Response(v interface{}) error                { return ErrNotSupported }
+func (e *entity) ResponseMid(mid uint64, v interface{}) error { return ErrNotSupported }
+func (e *entity) Close() error                                { return e.client.Close() }
+func (e *entity) Kick(reason string) error                    { return ErrNotSupported }
+func (e *entity) RemoteAddr() net.Addr                        { return e.client.conn.RemoteAddr() }
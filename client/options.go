@@ -0,0 +1,177 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"time"
+
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/serialize"
+	"github.com/revzim/amoeba/serialize/protobuf"
+)
+
+// Version is the client version sent to server in handshake
+const Version = "0.1.0"
+
+// Packet compression algorithms could be negotiated with server
+const (
+	Deflate = codec.Deflate
+	Zstd    = codec.Zstd
+)
+
+const (
+	defaultType      = "go-client"
+	defaultTimeout   = 5 * time.Second
+	defaultHeartbeat = 30 * time.Second
+)
+
+type (
+	options struct {
+		userData     interface{}
+		clientType   string   // client type sent in handshake
+		version      string   // client version sent in handshake
+		capabilities []string // capabilities supported by client
+		serializer   serialize.Serializer
+		pipeline     pipeline.Pipeline
+		dict         map[string]uint16
+		timeout      time.Duration
+		resume       string                 // resume token of the previous session
+		handlers     map[string]interface{} // push handlers registered before handshake
+		compress     []string               // compression algorithms in preference order
+		compressDict []byte                 // shared dictionary of deflate compression
+		keyExchange  bool                   // negotiate session cipher in handshake
+	}
+
+	// Option used to customize the client
+	Option func(options *options)
+)
+
+func defaultOptions() options {
+	return options{
+		serializer: protobuf.NewSerializer(),
+		clientType: defaultType,
+		version:    Version,
+		timeout:    defaultTimeout,
+	}
+}
+
+// WithUserData sets the custom data sent to server in handshake, the data
+// will be marshaled to JSON
+func WithUserData(data interface{}) Option {
+	return func(opt *options) {
+		opt.userData = data
+	}
+}
+
+// WithClientVersion sets the client type and version sent in handshake, the
+// server may reject the outdated version with ErrOutdated
+func WithClientVersion(clientType, version string) Option {
+	return func(opt *options) {
+		opt.clientType = clientType
+		opt.version = version
+	}
+}
+
+// WithCapabilities sets the capabilities supported by client, the ones also
+// supported by server could be retrieved by Client.Capabilities
+func WithCapabilities(capabilities ...string) Option {
+	return func(opt *options) {
+		opt.capabilities = append(opt.capabilities, capabilities...)
+	}
+}
+
+// WithSerializer sets the serializer of messages, protobuf is used by default
+func WithSerializer(serializer serialize.Serializer) Option {
+	return func(opt *options) {
+		opt.serializer = serializer
+	}
+}
+
+// WithPipeline sets the pipeline of client, the outbound functions process the
+// messages before sending and the inbound functions process the received ones
+func WithPipeline(pipeline pipeline.Pipeline) Option {
+	return func(opt *options) {
+		opt.pipeline = pipeline
+	}
+}
+
+// WithDictionary sets the routes dictionary which used to compress route, the
+// dictionary received in handshake replaces it. The server will skip
+// sending the dictionary if the hash of it equals to the one of server
+func WithDictionary(dict map[string]uint16) Option {
+	return func(opt *options) {
+		opt.dict = dict
+	}
+}
+
+// WithTimeout sets the timeout of dialing, handshake and blocking calls
+func WithTimeout(d time.Duration) Option {
+	return func(opt *options) {
+		opt.timeout = d
+	}
+}
+
+// WithResumeToken sets the resume token received from the previous connection,
+// the server will re-attach the client to the previous session if it has not
+// expired, see Client.Resumed
+func WithResumeToken(token string) Option {
+	return func(opt *options) {
+		opt.resume = token
+	}
+}
+
+// WithPushHandler registers the callback for the pushes of route before the
+// handshake, which receives the pushes sent right after the handshake, e.g.
+// the pending pushes of resumed session, see Client.On
+func WithPushHandler(route string, callback interface{}) Option {
+	return func(opt *options) {
+		if opt.handlers == nil {
+			opt.handlers = map[string]interface{}{}
+		}
+		opt.handlers[route] = callback
+	}
+}
+
+// WithCompression sets the packet compression algorithms in preference order,
+// the server selects the first one it supports, see Deflate and Zstd
+func WithCompression(algos ...string) Option {
+	return func(opt *options) {
+		opt.compress = algos
+	}
+}
+
+// WithCompressionDict sets the shared dictionary of deflate compression, it is
+// used only if the server holds the same dictionary
+func WithCompressionDict(dict []byte) Option {
+	return func(opt *options) {
+		opt.compressDict = dict
+	}
+}
+
+// WithKeyExchange requests the X25519 key exchange in handshake, the session
+// cipher is used by the pipeline stages of crypt.InstallStages or
+// crypt.InstallSequencedStages
+func WithKeyExchange() Option {
+	return func(opt *options) {
+		opt.keyExchange = true
+	}
+}
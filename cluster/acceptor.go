@@ -3,26 +3,58 @@ package cluster
 import (
 	"context"
 	"net"
+	"sync"
+	"sync/atomic"
 
 	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
 	"github.com/revzim/amoeba/internal/message"
 	"github.com/revzim/amoeba/mock"
+	"github.com/revzim/amoeba/serialize"
 	"github.com/revzim/amoeba/session"
 )
 
-type acceptor struct {
-	sid        int64
-	gateClient clusterpb.MemberClient
-	session    *session.Session
-	lastMid    uint64
-	rpcHandler rpcHandler
-	gateAddr   string
-}
+// callMidMask marks the message id allocated for remote call, which makes the
+// call ids never conflict with the request ids allocated by clients
+const callMidMask = uint64(1) << 63
+
+var callSeq uint64
+
+type (
+	acceptor struct {
+		sid        int64
+		gateClient clusterpb.MemberClient
+		session    *session.Session
+		lastMid    uint64
+		rpcHandler rpcHandler
+		rpcCaller  rpcCaller
+		gateAddr   string
+		nodeAddr   string       // service address of current node
+		pusher     *pushBatcher // queues messages to gate and coalesces pushes, nil if disabled
+		streamer   *streamer    // sends messages to gate over stream, nil if disabled
+		states     *stateQueue  // state deltas sent to gate
+
+		serializers  pendingSerializers                      // serializers of pending requests
+		serializerOf func(route string) serialize.Serializer // serializer of push, rpc and call route
+
+		muCalls sync.Mutex
+		calls   map[uint64]chan callResult // pending remote calls
+	}
+
+	callResult struct {
+		data []byte
+		err  error
+	}
+)
 
 // Push implements the session.NetworkEntity interface
 func (a *acceptor) Push(route string, v interface{}) error {
+	if a.gateClient == nil {
+		return ErrSessionNoGate
+	}
 	// TODO: buffer
-	data, err := message.Serialize(v)
+	data, err := message.SerializeWith(routeSerializer(a.serializerOf, route), v)
 	if err != nil {
 		return err
 	}
@@ -31,6 +63,14 @@ func (a *acceptor) Push(route string, v interface{}) error {
 		Route:     route,
 		Data:      data,
 	}
+	if a.streamer != nil {
+		return a.streamer.send(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Push{Push: request},
+		})
+	}
+	if a.pusher != nil {
+		return a.pusher.push(request)
+	}
 	_, err = a.gateClient.HandlePush(context.Background(), request)
 	return err
 }
@@ -38,7 +78,7 @@ func (a *acceptor) Push(route string, v interface{}) error {
 // RPC implements the session.NetworkEntity interface
 func (a *acceptor) RPC(route string, v interface{}) error {
 	// TODO: buffer
-	data, err := message.Serialize(v)
+	data, err := message.SerializeWith(routeSerializer(a.serializerOf, route), v)
 	if err != nil {
 		return err
 	}
@@ -51,6 +91,20 @@ func (a *acceptor) RPC(route string, v interface{}) error {
 	return nil
 }
 
+// Call implements the session.NetworkEntity interface
+func (a *acceptor) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	serializer := routeSerializer(a.serializerOf, route)
+	data, err := message.SerializeWith(serializer, v)
+	if err != nil {
+		return err
+	}
+	res, err := a.rpcCaller(ctx, a.session, route, data)
+	if err != nil {
+		return err
+	}
+	return message.DeserializeWith(serializer, res, reply)
+}
+
 // LastMid implements the session.NetworkEntity interface
 func (a *acceptor) LastMid() uint64 {
 	return a.lastMid
@@ -64,30 +118,150 @@ func (a *acceptor) Response(v interface{}) error {
 // ResponseMid implements the session.NetworkEntity interface
 func (a *acceptor) ResponseMid(mid uint64, v interface{}) error {
 	// TODO: buffer
-	data, err := message.Serialize(v)
+	data, err := message.SerializeWith(a.serializers.take(mid), v)
 	if err != nil {
 		return err
 	}
-	request := &clusterpb.ResponseMessage{
-		SessionId: a.sid,
-		Id:        mid,
-		Data:      data,
+
+	// Reply to the pending remote call instead of the gate
+	if mid&callMidMask != 0 {
+		a.finishCall(mid, callResult{data: data})
+		return nil
+	}
+	return a.response(&clusterpb.ResponseMessage{SessionId: a.sid, Id: mid, Data: data})
+}
+
+// responseError sends the error envelope as the response of request
+func (a *acceptor) responseError(mid uint64, data []byte) error {
+	a.serializers.take(mid)
+	return a.response(&clusterpb.ResponseMessage{SessionId: a.sid, Id: mid, Data: data, Error: true})
+}
+
+func (a *acceptor) response(request *clusterpb.ResponseMessage) error {
+	if a.gateClient == nil {
+		return ErrSessionNoGate
+	}
+	if a.streamer != nil {
+		return a.streamer.send(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Response{Response: request},
+		})
 	}
-	_, err = a.gateClient.HandleResponse(context.Background(), request)
+	// The response is queued after the pushes of session to keep order
+	if a.pusher != nil {
+		return a.pusher.send(a.sid, func(ctx context.Context, client clusterpb.MemberClient) error {
+			_, err := client.HandleResponse(ctx, request)
+			return err
+		})
+	}
+	_, err := a.gateClient.HandleResponse(context.Background(), request)
 	return err
 }
 
 // Close implements the session.NetworkEntity interface
 func (a *acceptor) Close() error {
+	if a.gateClient == nil {
+		return ErrSessionNoGate
+	}
 	// TODO: buffer
 	request := &clusterpb.CloseSessionRequest{
 		SessionId: a.sid,
 	}
+	if a.streamer != nil {
+		return a.streamer.send(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Close{Close: request},
+		})
+	}
+	if a.pusher != nil {
+		return a.pusher.closeSession(a.sid, func(ctx context.Context, client clusterpb.MemberClient) error {
+			_, err := client.CloseSession(ctx, request)
+			return err
+		})
+	}
 	_, err := a.gateClient.CloseSession(context.Background(), request)
 	return err
 }
 
+// Kick implements the session.NetworkEntity interface
+func (a *acceptor) Kick(reason string) error {
+	if a.gateClient == nil {
+		return ErrSessionNoGate
+	}
+	request := &clusterpb.KickRequest{
+		SessionId: a.sid,
+		Reason:    reason,
+	}
+	if a.streamer != nil {
+		return a.streamer.send(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Kick{Kick: request},
+		})
+	}
+	if a.pusher != nil {
+		return a.pusher.send(a.sid, func(ctx context.Context, client clusterpb.MemberClient) error {
+			_, err := client.Kick(ctx, request)
+			return err
+		})
+	}
+	_, err := a.gateClient.Kick(context.Background(), request)
+	return err
+}
+
+// Sync implements the session.Synchronizer interface
+func (a *acceptor) Sync(uid int64, delta session.Delta) {
+	if a.gateClient == nil {
+		return
+	}
+	a.states.push(uid, delta)
+}
+
+// syncState sends the state delta to gate, which merges and forwards it to
+// other backend nodes
+func (a *acceptor) syncState(uid int64, delta session.Delta) {
+	data, err := encodeState(delta)
+	if err != nil {
+		log.Println("Encode session state failed", err)
+		return
+	}
+	request := &clusterpb.SyncSessionRequest{
+		SessionId:   a.sid,
+		ServiceAddr: a.nodeAddr,
+		Uid:         uid,
+		Data:        data,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	if _, err := a.gateClient.SyncSession(ctx, request); err != nil {
+		log.Println("Sync session state to gate failed", a.gateAddr, err)
+	}
+}
+
 // RemoteAddr implements the session.NetworkEntity interface
 func (*acceptor) RemoteAddr() net.Addr {
 	return mock.NetAddr{}
 }
+
+// beginCall allocates a message id for the remote call and returns the channel
+// which the reply will be delivered to
+func (a *acceptor) beginCall() (uint64, chan callResult) {
+	mid := atomic.AddUint64(&callSeq, 1) | callMidMask
+	ch := make(chan callResult, 1)
+
+	a.muCalls.Lock()
+	if a.calls == nil {
+		a.calls = map[uint64]chan callResult{}
+	}
+	a.calls[mid] = ch
+	a.muCalls.Unlock()
+	return mid, ch
+}
+
+// finishCall delivers the result to the pending call, the result will be
+// discarded if the call has finished
+func (a *acceptor) finishCall(mid uint64, result callResult) {
+	a.muCalls.Lock()
+	ch, found := a.calls[mid]
+	delete(a.calls, mid)
+	a.muCalls.Unlock()
+	if found {
+		ch <- result
+	}
+}
@@ -21,13 +21,16 @@
 package cluster
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/revzim/amoeba/cluster/clusterpb"
 	"github.com/revzim/amoeba/internal/codec"
 	"github.com/revzim/amoeba/internal/env"
 	"github.com/revzim/amoeba/internal/log"
@@ -35,11 +38,13 @@ import (
 	"github.com/revzim/amoeba/internal/packet"
 	"github.com/revzim/amoeba/pipeline"
 	"github.com/revzim/amoeba/scheduler"
+	"github.com/revzim/amoeba/serialize"
 	"github.com/revzim/amoeba/session"
 )
 
 const (
-	agentWriteBacklog = 16
+	agentWriteBacklog   = 16
+	agentDeliverBacklog = 256 // max messages queued for the slow client
 )
 
 var (
@@ -50,22 +55,43 @@ var (
 	ErrBufferExceed = errors.New("session send buffer exceed")
 )
 
+// emptyDictionary compresses none of the routes, it is used before handshake
+var emptyDictionary = message.NewDictionary(nil)
+
 type (
 	// Agent corresponding a user, used for store raw conn information
 	agent struct {
 		// regular agent member
-		session  *session.Session    // session
-		conn     net.Conn            // low-level conn fd
-		lastMid  uint64              // last message id
-		state    int32               // current agent state
-		chDie    chan struct{}       // wait for close
-		chSend   chan pendingMessage // push message queue
-		lastAt   int64               // last heartbeat unix time stamp
-		decoder  *codec.Decoder      // binary decoder
-		pipeline pipeline.Pipeline
+		session     *session.Session    // session
+		conn        net.Conn            // low-level conn fd
+		lastMid     uint64              // last message id
+		state       int32               // current agent state
+		chDie       chan struct{}       // wait for close
+		chSend      chan pendingMessage // push message queue
+		lastAt      int64               // last heartbeat unix time stamp
+		decoder     *codec.Decoder      // binary decoder
+		compression atomic.Value        // *codec.Compression negotiated, nil if disabled
+		dictionary  atomic.Value        // *message.Dictionary sent in handshake response
+		pipeline    pipeline.Pipeline
 
 		rpcHandler rpcHandler
+		rpcCaller  rpcCaller
+		syncer     syncer
 		srv        reflect.Value // cached session reflect.Value
+
+		muDeliver  sync.Mutex
+		deliveries []pendingMessage // messages queued by deliver
+		delivering bool             // whether the deliveries are being sent
+
+		muPeers sync.RWMutex
+		peers   map[string]*stateQueue // backend nodes which hold the session state
+
+		serializers  pendingSerializers                      // serializers of pending requests
+		serializerOf func(route string) serialize.Serializer // serializer of push, rpc and call route
+
+		token    string       // resume token, empty if session resume disabled
+		detached int32        // closed by broken connection, the session could be resumed
+		parked   *parkedAgent // the detached agent which will be re-attached after handshake
 	}
 
 	pendingMessage struct {
@@ -73,11 +99,15 @@ type (
 		route   string       // message route(push)
 		mid     uint64       // response message id(response)
 		payload interface{}  // payload
+		kick    []byte       // kick packet, the agent will be closed after it sent
+		isError bool         // payload is an error envelope(response)
+
+		serializer serialize.Serializer // serializer of payload, nil for the default one
 	}
 )
 
 // Create new agent instance
-func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler) *agent {
+func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler, rpcCaller rpcCaller, syncer syncer) *agent {
 	a := &agent{
 		conn:       conn,
 		state:      statusStart,
@@ -87,6 +117,9 @@ func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler)
 		decoder:    codec.NewDecoder(),
 		pipeline:   pipeline,
 		rpcHandler: rpcHandler,
+		rpcCaller:  rpcCaller,
+		syncer:     syncer,
+		peers:      map[string]*stateQueue{},
 	}
 
 	// binding session
@@ -97,14 +130,90 @@ func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler)
 	return a
 }
 
-func (a *agent) send(m pendingMessage) (err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			err = ErrBrokenPipe
+// send queues the message to the write goroutine, the chSend is never closed,
+// so the senders are released by chDie once the agent closed
+func (a *agent) send(m pendingMessage) error {
+	select {
+	case a.chSend <- m:
+		return nil
+	case <-a.chDie:
+		return ErrBrokenPipe
+	}
+}
+
+// deliver queues the message in the delivery queue of session, the queued
+// messages are sent in order by a goroutine which exits once the queue drained,
+// so the slow client will not block the shared member streams
+func (a *agent) deliver(m pendingMessage) error {
+	if a.status() == statusClosed {
+		return ErrBrokenPipe
+	}
+
+	a.muDeliver.Lock()
+	if len(a.deliveries) >= agentDeliverBacklog {
+		a.muDeliver.Unlock()
+		return ErrBufferExceed
+	}
+	a.deliveries = append(a.deliveries, m)
+	if a.delivering {
+		a.muDeliver.Unlock()
+		return nil
+	}
+	a.delivering = true
+	a.muDeliver.Unlock()
+
+	go a.drainDeliveries()
+	return nil
+}
+
+func (a *agent) drainDeliveries() {
+	for {
+		a.muDeliver.Lock()
+		if len(a.deliveries) == 0 {
+			a.delivering = false
+			a.muDeliver.Unlock()
+			return
 		}
-	}()
-	a.chSend <- m
-	return
+		m := a.deliveries[0]
+		a.deliveries = a.deliveries[1:]
+		a.muDeliver.Unlock()
+
+		if err := a.send(m); err != nil {
+			a.muDeliver.Lock()
+			a.deliveries, a.delivering = nil, false
+			a.muDeliver.Unlock()
+			return
+		}
+	}
+}
+
+// setCompression publishes the compression negotiated by the read goroutine
+// to the write goroutine
+func (a *agent) setCompression(c *codec.Compression) {
+	a.compression.Store(c)
+}
+
+// getCompression returns the negotiated compression, nil if disabled
+func (a *agent) getCompression() *codec.Compression {
+	c, _ := a.compression.Load().(*codec.Compression)
+	return c
+}
+
+// setDictionary publishes the route dictionary sent to client, the messages of
+// agent are encoded and decoded with it rather than the global one, which may
+// be extended after handshake
+func (a *agent) setDictionary(d *message.Dictionary) {
+	a.dictionary.Store(d)
+}
+
+// getDictionary returns the route dictionary sent to client, the routes are
+// not compressed before handshake
+func (a *agent) getDictionary() *message.Dictionary {
+	d, _ := a.dictionary.Load().(*message.Dictionary)
+	if d == nil {
+		return emptyDictionary
+	}
+	return d
 }
 
 // LastMid implements the session.NetworkEntity interface
@@ -143,7 +252,7 @@ func (a *agent) RPC(route string, v interface{}) error {
 	}
 
 	// TODO: buffer
-	data, err := message.Serialize(v)
+	data, err := message.SerializeWith(routeSerializer(a.serializerOf, route), v)
 	if err != nil {
 		return err
 	}
@@ -156,6 +265,24 @@ func (a *agent) RPC(route string, v interface{}) error {
 	return nil
 }
 
+// Call, implementation for session.NetworkEntity interface
+func (a *agent) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	if a.status() == statusClosed {
+		return ErrBrokenPipe
+	}
+
+	serializer := routeSerializer(a.serializerOf, route)
+	data, err := message.SerializeWith(serializer, v)
+	if err != nil {
+		return err
+	}
+	res, err := a.rpcCaller(ctx, a.session, route, data)
+	if err != nil {
+		return err
+	}
+	return message.DeserializeWith(serializer, res, reply)
+}
+
 // Response, implementation for session.NetworkEntity interface
 // Response message to session
 func (a *agent) Response(v interface{}) error {
@@ -188,13 +315,51 @@ func (a *agent) ResponseMid(mid uint64, v interface{}) error {
 		}
 	}
 
-	return a.send(pendingMessage{typ: message.Response, mid: mid, payload: v})
+	return a.send(pendingMessage{typ: message.Response, mid: mid, payload: v, serializer: a.serializers.take(mid)})
+}
+
+// responseError sends the error envelope as the response of request
+func (a *agent) responseError(mid uint64, data []byte) error {
+	if a.status() == statusClosed {
+		return ErrBrokenPipe
+	}
+	a.serializers.take(mid)
+	return a.send(pendingMessage{typ: message.Response, mid: mid, payload: data, isError: true})
+}
+
+// Kick, implementation for session.NetworkEntity interface
+// Kick queues the kick packet after the pending messages, the agent will be
+// closed after the kick packet has been written, or closed immediately if the
+// delivery queue is full
+func (a *agent) Kick(reason string) error {
+	if a.status() == statusClosed {
+		return ErrBrokenPipe
+	}
+
+	p, err := kickData(reason)
+	if err != nil {
+		return err
+	}
+	if env.Debug {
+		log.Printf("Session kicked, ID=%d, UID=%d, Reason=%s", a.session.ID(), a.session.UID(), reason)
+	}
+	if err := a.deliver(pendingMessage{kick: p}); err != nil {
+		a.Close()
+		return err
+	}
+	return nil
 }
 
 // Close, implementation for session.NetworkEntity interface
 // Close closes the agent, clean inner state and close low-level connection.
 // Any blocked Read or Write operations will be unblocked and return errors.
 func (a *agent) Close() error {
+	return a.close(false)
+}
+
+// close closes the agent, the session will be detached instead of closed if
+// the connection is broken, which could be resumed by the client later
+func (a *agent) close(detach bool) error {
 	if a.status() == statusClosed {
 		return ErrCloseClosedSession
 	}
@@ -211,12 +376,89 @@ func (a *agent) Close() error {
 		// expect
 	default:
 		close(a.chDie)
-		scheduler.PushTask(func() { session.Lifetime.Close(a.session) })
+		if detach {
+			atomic.StoreInt32(&a.detached, 1)
+		} else {
+			scheduler.PushTask(func() { session.Lifetime.Close(a.session) })
+		}
 	}
 
 	return a.conn.Close()
 }
 
+func (a *agent) isDetached() bool {
+	return atomic.LoadInt32(&a.detached) == 1
+}
+
+// adopt takes over the session of detached agent, the messages sent to session
+// are still queued by the detached agent until the handshake completed
+func (a *agent) adopt(p *parkedAgent) {
+	old := p.agent
+	a.session = old.session
+	a.srv = reflect.ValueOf(a.session)
+	a.lastMid = old.lastMid
+	a.parked = p
+	old.serializers.moveTo(&a.serializers)
+
+	old.muPeers.RLock()
+	for addr := range old.peers {
+		a.subscribe(addr)
+	}
+	old.muPeers.RUnlock()
+}
+
+// resume re-attaches the adopted session to current agent
+func (a *agent) resume() error {
+	if a.parked == nil {
+		return nil
+	}
+	err := a.parked.attach(a)
+	a.session.Attach(a)
+	a.parked = nil
+	return err
+}
+
+// Sync, implementation for session.Synchronizer interface
+func (a *agent) Sync(uid int64, delta session.Delta) {
+	a.syncPeers("", uid, delta)
+}
+
+// subscribe records the backend node which holds the session state
+func (a *agent) subscribe(addr string) {
+	a.muPeers.Lock()
+	defer a.muPeers.Unlock()
+	if _, found := a.peers[addr]; found {
+		return
+	}
+	a.peers[addr] = newStateQueue(func(uid int64, delta session.Delta) {
+		if a.syncer == nil {
+			return
+		}
+		data, err := encodeState(delta)
+		if err != nil {
+			log.Println("Encode session state failed", err)
+			return
+		}
+		a.syncer(addr, &clusterpb.SyncSessionRequest{
+			SessionId: a.session.ID(),
+			Uid:       uid,
+			Data:      data,
+		})
+	})
+}
+
+// syncPeers queues the state delta to all backend nodes holding the session
+// except the origin node which the changes come from
+func (a *agent) syncPeers(origin string, uid int64, delta session.Delta) {
+	a.muPeers.RLock()
+	defer a.muPeers.RUnlock()
+	for addr, q := range a.peers {
+		if addr != origin {
+			q.push(uid, delta)
+		}
+	}
+}
+
 // RemoteAddr, implementation for session.NetworkEntity interface
 // returns the remote network address.
 func (a *agent) RemoteAddr() net.Addr {
@@ -242,7 +484,6 @@ func (a *agent) write() {
 	// clean func
 	defer func() {
 		ticker.Stop()
-		close(a.chSend)
 		close(chWrite)
 		a.Close()
 		if env.Debug {
@@ -256,6 +497,8 @@ func (a *agent) write() {
 			deadline := time.Now().Add(-2 * env.Heartbeat).Unix()
 			if atomic.LoadInt64(&a.lastAt) < deadline {
 				log.Printf("Session heartbeat timeout, LastTime=%d, Deadline=%d", atomic.LoadInt64(&a.lastAt), deadline)
+				// the link died silently, detach the session for resume
+				a.close(true)
 				return
 			}
 			chWrite <- hbd
@@ -268,7 +511,18 @@ func (a *agent) write() {
 			}
 
 		case data := <-a.chSend:
-			payload, err := message.Serialize(data.payload)
+			if data.kick != nil {
+				if _, err := a.conn.Write(data.kick); err != nil {
+					log.Println(err.Error())
+				}
+				return
+			}
+
+			serializer := data.serializer
+			if serializer == nil && data.typ == message.Push && a.serializerOf != nil {
+				serializer = a.serializerOf(data.route)
+			}
+			payload, err := message.SerializeWith(serializer, data.payload)
 			if err != nil {
 				switch data.typ {
 				case message.Push:
@@ -288,6 +542,7 @@ func (a *agent) write() {
 				Data:  payload,
 				Route: data.route,
 				ID:    data.mid,
+				Error: data.isError,
 			}
 			if pipe := a.pipeline; pipe != nil {
 				err := pipe.Outbound().Process(a.session, m)
@@ -297,20 +552,30 @@ func (a *agent) write() {
 				}
 			}
 
-			em, err := m.Encode()
+			// build the message and packet header in a pooled buffer, which
+			// is kept on error because Append returns nil
+			buf := codec.NewBuffer()
+			b, err := a.getDictionary().Append(buf.B, m)
 			if err != nil {
+				buf.Free()
 				log.Println(err.Error())
 				break
 			}
-			// log.Println("em", string(em))
-			// packet encode
-			p, err := codec.Encode(packet.Data, em)
+			buf.B = b
+			p, err := a.getCompression().Frame(packet.Data, buf)
 			if err != nil {
+				buf.Free()
 				log.Println(err)
 				break
 			}
-			// log.Println("packet", string(p))
-			chWrite <- p
+			// write directly, the write goroutine would be blocked by itself
+			// if chWrite is full
+			_, err = a.conn.Write(p)
+			buf.Free()
+			if err != nil {
+				log.Println(err.Error())
+				return
+			}
 
 		case <-a.chDie: // agent closed signal
 			return
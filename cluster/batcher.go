@@ -0,0 +1,235 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+)
+
+const (
+	// maxPushBatch is the max number of push messages in a batch, the batch
+	// will be flushed immediately if it is full
+	maxPushBatch = 256
+	// pushBacklog is the max number of messages queued to a gate, the messages
+	// are rejected if the gate can not keep up with them
+	pushBacklog = 1 << 12
+)
+
+type (
+	// pushBatcher queues the messages to the same gate and sends them in order
+	// by its own goroutine, the consecutive push messages within the flush
+	// window are coalesced and sent with one HandlePushBatch rpc. The other
+	// messages, e.g: response, flush the pushes queued before them, so the
+	// messages of a session keep in order without blocking the handler
+	pushBatcher struct {
+		sync.Mutex
+		client   clusterpb.MemberClient
+		window   time.Duration
+		pending  []batchItem
+		failed   map[int64]error // session id => error of the failed messages
+		chSignal chan struct{}
+		chDie    chan struct{}
+		chDone   chan struct{}
+		closed   bool
+	}
+
+	// batchItem is a queued message of session, either a push message or
+	// other message sent by call
+	batchItem struct {
+		sid     int64
+		push    *clusterpb.PushMessage
+		call    func(ctx context.Context, client clusterpb.MemberClient) error
+		closing bool // the last message of the closed session
+	}
+)
+
+func newPushBatcher(client clusterpb.MemberClient, window time.Duration) *pushBatcher {
+	b := &pushBatcher{
+		client:   client,
+		window:   window,
+		failed:   map[int64]error{},
+		chSignal: make(chan struct{}, 1),
+		chDie:    make(chan struct{}),
+		chDone:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// push queues the push message, the error of the messages of the session
+// failed to send since last queued is returned, and the message is still
+// queued. ErrBatcherBackpressure is returned if the backlog is full
+func (b *pushBatcher) push(msg *clusterpb.PushMessage) error {
+	return b.queue(batchItem{sid: msg.SessionId, push: msg})
+}
+
+// send queues the message sent by call after the pending pushes, the error is
+// reported like push
+func (b *pushBatcher) send(sid int64, call func(ctx context.Context, client clusterpb.MemberClient) error) error {
+	return b.queue(batchItem{sid: sid, call: call})
+}
+
+// closeSession queues the message which closes the session, it is queued even
+// if the backlog is full, and the failures of session are dropped after sent
+func (b *pushBatcher) closeSession(sid int64, call func(ctx context.Context, client clusterpb.MemberClient) error) error {
+	return b.queue(batchItem{sid: sid, call: call, closing: true})
+}
+
+func (b *pushBatcher) queue(item batchItem) error {
+	b.Lock()
+	if b.closed {
+		b.Unlock()
+		return ErrBatcherClosed
+	}
+	if len(b.pending) >= pushBacklog && !item.closing {
+		b.Unlock()
+		return ErrBatcherBackpressure
+	}
+	b.pending = append(b.pending, item)
+	err := b.failed[item.sid]
+	delete(b.failed, item.sid)
+	b.Unlock()
+
+	select {
+	case b.chSignal <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// ready reports whether the pending messages should be sent without waiting
+// for the flush window
+func (b *pushBatcher) ready() bool {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.pending) >= maxPushBatch {
+		return true
+	}
+	for _, item := range b.pending {
+		if item.push == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *pushBatcher) run() {
+	defer close(b.chDone)
+	for {
+		select {
+		case <-b.chSignal:
+		case <-b.chDie:
+			b.flush()
+			return
+		}
+
+		// wait for more pushes within the window
+		timer := time.NewTimer(b.window)
+	wait:
+		for !b.ready() {
+			select {
+			case <-b.chSignal:
+			case <-timer.C:
+				break wait
+			case <-b.chDie:
+				break wait
+			}
+		}
+		timer.Stop()
+		b.flush()
+	}
+}
+
+// flush sends all pending messages in order, the consecutive pushes are sent
+// in batches
+func (b *pushBatcher) flush() {
+	b.Lock()
+	items := b.pending
+	b.pending = nil
+	b.Unlock()
+
+	for len(items) > 0 {
+		n := 0
+		for n < len(items) && n < maxPushBatch && items[n].push != nil {
+			n++
+		}
+		if n > 0 {
+			b.sendBatch(items[:n])
+			items = items[n:]
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+		if err := items[0].call(ctx, b.client); err != nil {
+			b.fail(items[:1], err)
+		}
+		cancel()
+		if items[0].closing {
+			// no more messages of the closed session will take the failures
+			b.Lock()
+			delete(b.failed, items[0].sid)
+			b.Unlock()
+		}
+		items = items[1:]
+	}
+}
+
+func (b *pushBatcher) sendBatch(items []batchItem) {
+	pushes := make([]*clusterpb.PushMessage, 0, len(items))
+	for _, item := range items {
+		pushes = append(pushes, item.push)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	if _, err := b.client.HandlePushBatch(ctx, &clusterpb.PushBatch{Pushes: pushes}); err != nil {
+		b.fail(items, err)
+	}
+}
+
+// fail records the error for the sessions of failed messages, which will be
+// returned by their next queued message
+func (b *pushBatcher) fail(items []batchItem, err error) {
+	log.Println("Send queued messages to gate failed", len(items), err)
+	b.Lock()
+	defer b.Unlock()
+	for _, item := range items {
+		b.failed[item.sid] = err
+	}
+}
+
+// close sends the pending messages and stops the batcher, the messages queued
+// after closed are rejected
+func (b *pushBatcher) close() {
+	b.Lock()
+	if b.closed {
+		b.Unlock()
+		return
+	}
+	b.closed = true
+	b.Unlock()
+	close(b.chDie)
+	<-b.chDone
+}
@@ -0,0 +1,324 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/io"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+	"google.golang.org/grpc"
+)
+
+type WorldComponent struct{ component.Base }
+
+func (c *WorldComponent) Update(s *session.Session, _ *testdata.Ping) error {
+	for i := 0; i < 10; i++ {
+		if err := s.Push("update", &testdata.Pong{Content: fmt.Sprintf("frame %d", i)}); err != nil {
+			return err
+		}
+	}
+	return s.Response(&testdata.Pong{Content: "done"})
+}
+
+func (c *WorldComponent) Report(s *session.Session, _ *testdata.Ping) error {
+	content := "delivered"
+	if err := s.Push("update", &testdata.Pong{Content: "report"}); err != nil {
+		content = err.Error()
+	}
+	return s.Response(&testdata.Pong{Content: content})
+}
+
+func (c *WorldComponent) Quit(s *session.Session, _ *testdata.Ping) error {
+	s.Push("update", &testdata.Pong{Content: "bye"})
+	s.Close()
+	return nil
+}
+
+// FloodComponent pushes to the session until the push is rejected, and reports
+// the error by chErr
+type FloodComponent struct {
+	component.Base
+	chErr chan error
+}
+
+func (c *FloodComponent) Flood(s *session.Session, _ *testdata.Ping) error {
+	var err error
+	for i := 0; i < 4*cluster.PushBacklog && err == nil; i++ {
+		err = s.Push("flood", &testdata.Pong{Content: "wave"})
+	}
+	c.chErr <- err
+	return nil
+}
+
+// pushCounter is a gate which counts the push batches received
+type pushCounter struct {
+	clusterpb.UnimplementedMemberServer
+	batches, pushes int32
+	chResponse      chan struct{}
+}
+
+func (p *pushCounter) HandlePushBatch(_ context.Context, req *clusterpb.PushBatch) (*clusterpb.MemberHandleResponse, error) {
+	atomic.AddInt32(&p.batches, 1)
+	atomic.AddInt32(&p.pushes, int32(len(req.Pushes)))
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (p *pushCounter) HandleResponse(context.Context, *clusterpb.ResponseMessage) (*clusterpb.MemberHandleResponse, error) {
+	p.chResponse <- struct{}{}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (s *nodeSuite) TestPushBatch(c *C) {
+	masterComps := &component.Components{}
+	masterComps.Register(&MasterComponent{})
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: masterComps},
+		ServiceAddr: "127.0.0.1:18550",
+	}
+	c.Assert(master.Startup(), IsNil)
+
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:18550",
+			ClientAddr:    "127.0.0.1:18552",
+			Components:    &component.Components{},
+		},
+		ServiceAddr: "127.0.0.1:18551",
+	}
+	c.Assert(gate.Startup(), IsNil)
+
+	worldComps := &component.Components{}
+	worldComps.Register(&WorldComponent{})
+	world := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:18550",
+			PushBatchWindow: 20 * time.Millisecond,
+			Components:      worldComps,
+		},
+		ServiceAddr: "127.0.0.1:18553",
+	}
+	c.Assert(world.Startup(), IsNil)
+
+	connector := io.NewConnector()
+	chWait := make(chan struct{})
+	connector.OnConnected(func() {
+		chWait <- struct{}{}
+	})
+	c.Assert(connector.Start("127.0.0.1:18552"), IsNil)
+	<-chWait
+
+	onResult := make(chan string, 16)
+	connector.On("update", func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	err := connector.Request("WorldComponent.Update", &testdata.Ping{}, func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	c.Assert(err, IsNil)
+
+	// The pushes are flushed before response and keep in order
+	for i := 0; i < 10; i++ {
+		c.Assert(strings.Contains(<-onResult, fmt.Sprintf("frame %d", i)), IsTrue)
+	}
+	c.Assert(strings.Contains(<-onResult, "done"), IsTrue)
+
+	world.Shutdown()
+	gate.Shutdown()
+	master.Shutdown()
+}
+
+func (s *nodeSuite) TestPushBatchCount(c *C) {
+	worldComps := &component.Components{}
+	worldComps.Register(&WorldComponent{})
+	world := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			PushBatchWindow: 20 * time.Millisecond,
+			Components:      worldComps,
+		},
+		ServiceAddr: "127.0.0.1:31561",
+	}
+	c.Assert(world.Startup(), IsNil)
+	defer world.Shutdown()
+
+	gate := &pushCounter{chResponse: make(chan struct{}, 1)}
+	listener, err := net.Listen("tcp", "127.0.0.1:31562")
+	c.Assert(err, IsNil)
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:31561", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = clusterpb.NewMemberClient(conn).HandleRequest(context.Background(), &clusterpb.RequestMessage{
+		GateAddr:  "127.0.0.1:31562",
+		SessionId: 1,
+		Route:     "WorldComponent.Update",
+	})
+	c.Assert(err, IsNil)
+
+	// The pushes within the window are sent to gate with one rpc before the
+	// response
+	select {
+	case <-gate.chResponse:
+	case <-time.After(time.Second):
+		c.Fatal("response timeout")
+	}
+	c.Assert(atomic.LoadInt32(&gate.batches), Equals, int32(1))
+	c.Assert(atomic.LoadInt32(&gate.pushes), Equals, int32(10))
+}
+
+// overloadedGate is a gate which rejects all push batches
+type overloadedGate struct {
+	clusterpb.UnimplementedMemberServer
+	chResponse chan []byte
+	chClose    chan struct{}
+}
+
+func (g *overloadedGate) HandlePushBatch(context.Context, *clusterpb.PushBatch) (*clusterpb.MemberHandleResponse, error) {
+	return nil, fmt.Errorf("gate overloaded")
+}
+
+func (g *overloadedGate) CloseSession(context.Context, *clusterpb.CloseSessionRequest) (*clusterpb.CloseSessionResponse, error) {
+	g.chClose <- struct{}{}
+	return nil, fmt.Errorf("gate overloaded")
+}
+
+func (g *overloadedGate) HandleResponse(_ context.Context, req *clusterpb.ResponseMessage) (*clusterpb.MemberHandleResponse, error) {
+	g.chResponse <- req.Data
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (s *nodeSuite) TestPushBatchFailure(c *C) {
+	worldComps := &component.Components{}
+	worldComps.Register(&WorldComponent{})
+	world := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			PushBatchWindow: 20 * time.Millisecond,
+			Components:      worldComps,
+		},
+		ServiceAddr: "127.0.0.1:31574",
+	}
+	c.Assert(world.Startup(), IsNil)
+	defer world.Shutdown()
+
+	gate := &overloadedGate{chResponse: make(chan []byte, 1), chClose: make(chan struct{}, 1)}
+	listener, err := net.Listen("tcp", "127.0.0.1:31575")
+	c.Assert(err, IsNil)
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:31574", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	report := func(mid uint64) string {
+		_, err := clusterpb.NewMemberClient(conn).HandleRequest(context.Background(), &clusterpb.RequestMessage{
+			GateAddr:  "127.0.0.1:31575",
+			SessionId: 1,
+			Id:        mid,
+			Route:     "WorldComponent.Report",
+		})
+		c.Assert(err, IsNil)
+		select {
+		case data := <-gate.chResponse:
+			return string(data)
+		case <-time.After(time.Second):
+			c.Fatal("response timeout")
+		}
+		return ""
+	}
+
+	// the failure of the batch is returned by the next message of session
+	c.Assert(strings.Contains(report(1), "delivered"), IsTrue)
+	c.Assert(strings.Contains(report(2), "gate overloaded"), IsTrue)
+
+	// the failures of the closed session are dropped
+	_, err = clusterpb.NewMemberClient(conn).HandleRequest(context.Background(), &clusterpb.RequestMessage{
+		GateAddr:  "127.0.0.1:31575",
+		SessionId: 1,
+		Route:     "WorldComponent.Quit",
+	})
+	c.Assert(err, IsNil)
+	select {
+	case <-gate.chClose:
+	case <-time.After(time.Second):
+		c.Fatal("close timeout")
+	}
+	retry(c, func() error {
+		if n := world.PushFailures(); n > 0 {
+			return fmt.Errorf("%d push failures", n)
+		}
+		return nil
+	})
+}
+
+// stalledGate is a gate which holds the push batches until released
+type stalledGate struct {
+	clusterpb.UnimplementedMemberServer
+	chRelease chan struct{}
+}
+
+func (g *stalledGate) HandlePushBatch(ctx context.Context, _ *clusterpb.PushBatch) (*clusterpb.MemberHandleResponse, error) {
+	select {
+	case <-g.chRelease:
+	case <-ctx.Done():
+	}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (s *nodeSuite) TestPushBatchBacklog(c *C) {
+	flood := &FloodComponent{chErr: make(chan error, 1)}
+	comps := &component.Components{}
+	comps.Register(flood)
+	world := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			PushBatchWindow: 20 * time.Millisecond,
+			Components:      comps,
+		},
+		ServiceAddr: "127.0.0.1:31578",
+	}
+	c.Assert(world.Startup(), IsNil)
+	defer world.Shutdown()
+
+	gate := &stalledGate{chRelease: make(chan struct{})}
+	listener, err := net.Listen("tcp", "127.0.0.1:31579")
+	c.Assert(err, IsNil)
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+	defer close(gate.chRelease)
+
+	conn, err := grpc.Dial("127.0.0.1:31578", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = clusterpb.NewMemberClient(conn).HandleRequest(context.Background(), &clusterpb.RequestMessage{
+		GateAddr:  "127.0.0.1:31579",
+		SessionId: 1,
+		Route:     "FloodComponent.Flood",
+	})
+	c.Assert(err, IsNil)
+
+	// the pushes are rejected once the backlog of stalled gate is full
+	select {
+	case err := <-flood.chErr:
+		c.Assert(err, Equals, cluster.ErrBatcherBackpressure)
+	case <-time.After(time.Second):
+		c.Fatal("flood timeout")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/revzim/amoeba/cluster/clusterpb"
 	"github.com/revzim/amoeba/internal/log"
@@ -38,100 +39,155 @@ type cluster struct {
 	currentNode *Node
 	rpcClient   *rpcClient
 	members     []*Member
+	masters     []string // service addresses of all master nodes
+
+	// replication states, which are only used in master nodes
+	term        uint64               // current election term
+	version     uint64               // members version, increased by leader on every change
+	memberTerm  uint64               // term of the leader which sent the member list applied
+	leader      string               // leader address of current term
+	votedFor    string               // candidate address voted in current term
+	lastContact time.Time            // last time heard from leader, voted or acknowledged by quorum
+	acks        map[string]time.Time // last time the followers acknowledged current leader
+	timeout     time.Duration        // randomized election timeout
+	registering int32                // whether registering current master to leader
+	chDie       chan struct{}
 }
 
 func newCluster(currentNode *Node) *cluster {
-	return &cluster{currentNode: currentNode}
+	return &cluster{currentNode: currentNode, chDie: make(chan struct{})}
 }
 
 // Register implements the MasterServer gRPC service
-func (c *cluster) Register(_ context.Context, req *clusterpb.RegisterRequest) (*clusterpb.RegisterResponse, error) {
+func (c *cluster) Register(ctx context.Context, req *clusterpb.RegisterRequest) (*clusterpb.RegisterResponse, error) {
 	if req.MemberInfo == nil {
 		return nil, ErrInvalidRegisterReq
 	}
 
-	resp := &clusterpb.RegisterResponse{}
+	// Followers forward the request to leader transparently
+	if !c.isLeader() {
+		client, err := c.leaderClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.Register(ctx, req)
+	}
+
+	// The member is added before notifying the registered nodes, so the
+	// concurrent registrations of the same address are rejected
+	addr := req.MemberInfo.ServiceAddr
+	c.Lock()
 	for _, m := range c.members {
-		if m.memberInfo.ServiceAddr == req.MemberInfo.ServiceAddr {
-			return nil, fmt.Errorf("address %s has registered", req.MemberInfo.ServiceAddr)
+		if m.memberInfo.ServiceAddr == addr {
+			c.Unlock()
+			return nil, fmt.Errorf("address %s has registered", addr)
 		}
 	}
+	members := c.members
+	c.members = append(members[:len(members):len(members)], &Member{
+		isMaster:      c.isMasterAddr(addr),
+		memberInfo:    req.MemberInfo,
+		lastHeartbeat: time.Now(),
+	})
+	c.version++
+	resp := &clusterpb.RegisterResponse{Masters: c.masters}
+	c.Unlock()
 
 	// Notify registered node to update remote services
 	newMember := &clusterpb.NewMemberRequest{MemberInfo: req.MemberInfo}
-	for _, m := range c.members {
+	for _, m := range members {
 		resp.Members = append(resp.Members, m.memberInfo)
 		if m.isMaster {
 			continue
 		}
 		pool, err := c.rpcClient.getConnPool(m.memberInfo.ServiceAddr)
-		if err != nil {
-			return nil, err
+		if err == nil {
+			client := clusterpb.NewMemberClient(pool.Get())
+			_, err = client.NewMember(context.Background(), newMember)
 		}
-		client := clusterpb.NewMemberClient(pool.Get())
-		_, err = client.NewMember(context.Background(), newMember)
 		if err != nil {
+			// roll back the registration, the member will retry registering
+			c.removeMember(addr)
 			return nil, err
 		}
 	}
 
-	log.Println("New peer register to cluster", req.MemberInfo.ServiceAddr)
+	log.Println("New peer register to cluster", addr)
 
 	// Register services to current node
-	c.currentNode.handler.addRemoteService(req.MemberInfo)
-	c.Lock()
-	c.members = append(c.members, &Member{isMaster: false, memberInfo: req.MemberInfo})
-	c.Unlock()
+	if addr != c.currentNode.ServiceAddr {
+		c.currentNode.handler.addRemoteService(req.MemberInfo)
+	}
+	c.replicate()
 	return resp, nil
 }
 
 // Register implements the MasterServer gRPC service
-func (c *cluster) Unregister(_ context.Context, req *clusterpb.UnregisterRequest) (*clusterpb.UnregisterResponse, error) {
+func (c *cluster) Unregister(ctx context.Context, req *clusterpb.UnregisterRequest) (*clusterpb.UnregisterResponse, error) {
 	if req.ServiceAddr == "" {
 		return nil, ErrInvalidRegisterReq
 	}
 
+	// Followers forward the request to leader transparently
+	if !c.isLeader() {
+		client, err := c.leaderClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.Unregister(ctx, req)
+	}
+
+	if !c.removeMember(req.ServiceAddr) {
+		return nil, fmt.Errorf("address %s has not registered", req.ServiceAddr)
+	}
+	log.Println("Exists peer unregister to cluster", req.ServiceAddr)
+	return &clusterpb.UnregisterResponse{}, nil
+}
+
+// removeMember removes the member from cluster and notifies all registered nodes
+// to update remote services, the notification is best-effort because some of the
+// nodes may be already dead
+func (c *cluster) removeMember(addr string) bool {
+	c.Lock()
 	var index = -1
-	resp := &clusterpb.UnregisterResponse{}
 	for i, m := range c.members {
-		if m.memberInfo.ServiceAddr == req.ServiceAddr {
+		if m.memberInfo.ServiceAddr == addr {
 			index = i
 			break
 		}
 	}
 	if index < 0 {
-		return nil, fmt.Errorf("address %s has  notregistered", req.ServiceAddr)
+		c.Unlock()
+		return false
 	}
+	members := make([]*Member, 0, len(c.members)-1)
+	members = append(members, c.members[:index]...)
+	members = append(members, c.members[index+1:]...)
+	c.members = members
+	c.version++
+	c.Unlock()
 
-	// Notify registered node to update remote services
-	delMember := &clusterpb.DelMemberRequest{ServiceAddr: req.ServiceAddr}
-	for _, m := range c.members {
-		if m.MemberInfo().ServiceAddr == c.currentNode.ServiceAddr {
+	delMember := &clusterpb.DelMemberRequest{ServiceAddr: addr}
+	for _, m := range members {
+		if m.isMaster {
 			continue
 		}
 		pool, err := c.rpcClient.getConnPool(m.memberInfo.ServiceAddr)
 		if err != nil {
-			return nil, err
+			log.Println("Notify member removed failed", m.memberInfo.ServiceAddr, err)
+			continue
 		}
-		client := clusterpb.NewMemberClient(pool.Get())
-		_, err = client.DelMember(context.Background(), delMember)
+		ctx, cancel := context.WithTimeout(context.Background(), c.heartbeatInterval())
+		_, err = clusterpb.NewMemberClient(pool.Get()).DelMember(ctx, delMember)
+		cancel()
 		if err != nil {
-			return nil, err
+			log.Println("Notify member removed failed", m.memberInfo.ServiceAddr, err)
 		}
 	}
 
-	log.Println("Exists peer unregister to cluster", req.ServiceAddr)
-
-	// Register services to current node
-	c.currentNode.handler.delMember(req.ServiceAddr)
-	c.Lock()
-	if index == len(c.members)-1 {
-		c.members = c.members[:index]
-	} else {
-		c.members = append(c.members[:index], c.members[index+1:]...)
-	}
-	c.Unlock()
-	return resp, nil
+	c.currentNode.delMember(addr)
+	c.replicate()
+	return true
 }
 
 func (c *cluster) setRpcClient(client *rpcClient) {
@@ -148,14 +204,22 @@ func (c *cluster) remoteAddrs() []string {
 	return addrs
 }
 
-func (c *cluster) initMembers(members []*clusterpb.MemberInfo) {
+func (c *cluster) setMasters(masters []string) {
 	c.Lock()
+	c.masters = masters
+	c.Unlock()
+}
+
+func (c *cluster) masterAddrs() []string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.masters
+}
+
+func (c *cluster) initMembers(members []*clusterpb.MemberInfo) {
 	for _, info := range members {
-		c.members = append(c.members, &Member{
-			memberInfo: info,
-		})
+		c.addMember(info)
 	}
-	c.Unlock()
 }
 
 func (c *cluster) addMember(info *clusterpb.MemberInfo) {
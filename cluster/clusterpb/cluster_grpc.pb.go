@@ -20,6 +20,9 @@ const _ = grpc.SupportPackageIsVersion7
 type MasterClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Unregister(ctx context.Context, in *UnregisterRequest, opts ...grpc.CallOption) (*UnregisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error)
+	AppendMembers(ctx context.Context, in *AppendMembersRequest, opts ...grpc.CallOption) (*AppendMembersResponse, error)
 }
 
 type masterClient struct {
@@ -48,12 +51,42 @@ func (c *masterClient) Unregister(ctx context.Context, in *UnregisterRequest, op
 	return out, nil
 }
 
+func (c *masterClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Master/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error) {
+	out := new(RequestVoteResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Master/RequestVote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) AppendMembers(ctx context.Context, in *AppendMembersRequest, opts ...grpc.CallOption) (*AppendMembersResponse, error) {
+	out := new(AppendMembersResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Master/AppendMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MasterServer is the server API for Master service.
 // All implementations should embed UnimplementedMasterServer
 // for forward compatibility
 type MasterServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error)
+	AppendMembers(context.Context, *AppendMembersRequest) (*AppendMembersResponse, error)
 }
 
 // UnimplementedMasterServer should be embedded to have forward compatible implementations.
@@ -66,6 +99,15 @@ func (UnimplementedMasterServer) Register(context.Context, *RegisterRequest) (*R
 func (UnimplementedMasterServer) Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Unregister not implemented")
 }
+func (UnimplementedMasterServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMasterServer) RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
+func (UnimplementedMasterServer) AppendMembers(context.Context, *AppendMembersRequest) (*AppendMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendMembers not implemented")
+}
 
 // UnsafeMasterServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to MasterServer will
@@ -114,6 +156,60 @@ func _Master_Unregister_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Master_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Master/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Master/RequestVote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RequestVote(ctx, req.(*RequestVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_AppendMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).AppendMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Master/AppendMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).AppendMembers(ctx, req.(*AppendMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Master_ServiceDesc is the grpc.ServiceDesc for Master service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -129,6 +225,18 @@ var Master_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Unregister",
 			Handler:    _Master_Unregister_Handler,
 		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Master_Heartbeat_Handler,
+		},
+		{
+			MethodName: "RequestVote",
+			Handler:    _Master_RequestVote_Handler,
+		},
+		{
+			MethodName: "AppendMembers",
+			Handler:    _Master_AppendMembers_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cluster.proto",
@@ -141,11 +249,18 @@ type MemberClient interface {
 	HandleRequest(ctx context.Context, in *RequestMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
 	HandleNotify(ctx context.Context, in *NotifyMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
 	HandlePush(ctx context.Context, in *PushMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	HandlePushBatch(ctx context.Context, in *PushBatch, opts ...grpc.CallOption) (*MemberHandleResponse, error)
 	HandleResponse(ctx context.Context, in *ResponseMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	HandleCall(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	HandleMulticast(ctx context.Context, in *MulticastMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	Stream(ctx context.Context, opts ...grpc.CallOption) (Member_StreamClient, error)
 	NewMember(ctx context.Context, in *NewMemberRequest, opts ...grpc.CallOption) (*NewMemberResponse, error)
 	DelMember(ctx context.Context, in *DelMemberRequest, opts ...grpc.CallOption) (*DelMemberResponse, error)
 	SessionClosed(ctx context.Context, in *SessionClosedRequest, opts ...grpc.CallOption) (*SessionClosedResponse, error)
 	CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionResponse, error)
+	FetchSession(ctx context.Context, in *FetchSessionRequest, opts ...grpc.CallOption) (*FetchSessionResponse, error)
+	SyncSession(ctx context.Context, in *SyncSessionRequest, opts ...grpc.CallOption) (*SyncSessionResponse, error)
+	Kick(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*KickResponse, error)
 }
 
 type memberClient struct {
@@ -183,6 +298,15 @@ func (c *memberClient) HandlePush(ctx context.Context, in *PushMessage, opts ...
 	return out, nil
 }
 
+func (c *memberClient) HandlePushBatch(ctx context.Context, in *PushBatch, opts ...grpc.CallOption) (*MemberHandleResponse, error) {
+	out := new(MemberHandleResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandlePushBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *memberClient) HandleResponse(ctx context.Context, in *ResponseMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error) {
 	out := new(MemberHandleResponse)
 	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleResponse", in, out, opts...)
@@ -192,6 +316,55 @@ func (c *memberClient) HandleResponse(ctx context.Context, in *ResponseMessage,
 	return out, nil
 }
 
+func (c *memberClient) HandleCall(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleCall", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) HandleMulticast(ctx context.Context, in *MulticastMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error) {
+	out := new(MemberHandleResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleMulticast", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) Stream(ctx context.Context, opts ...grpc.CallOption) (Member_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Member_ServiceDesc.Streams[0], "/clusterpb.Member/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &memberStreamClient{stream}
+	return x, nil
+}
+
+type Member_StreamClient interface {
+	Send(*StreamMessage) error
+	Recv() (*StreamMessage, error)
+	grpc.ClientStream
+}
+
+type memberStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *memberStreamClient) Send(m *StreamMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *memberStreamClient) Recv() (*StreamMessage, error) {
+	m := new(StreamMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *memberClient) NewMember(ctx context.Context, in *NewMemberRequest, opts ...grpc.CallOption) (*NewMemberResponse, error) {
 	out := new(NewMemberResponse)
 	err := c.cc.Invoke(ctx, "/clusterpb.Member/NewMember", in, out, opts...)
@@ -228,6 +401,33 @@ func (c *memberClient) CloseSession(ctx context.Context, in *CloseSessionRequest
 	return out, nil
 }
 
+func (c *memberClient) FetchSession(ctx context.Context, in *FetchSessionRequest, opts ...grpc.CallOption) (*FetchSessionResponse, error) {
+	out := new(FetchSessionResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/FetchSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) SyncSession(ctx context.Context, in *SyncSessionRequest, opts ...grpc.CallOption) (*SyncSessionResponse, error) {
+	out := new(SyncSessionResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/SyncSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) Kick(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*KickResponse, error) {
+	out := new(KickResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/Kick", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MemberServer is the server API for Member service.
 // All implementations should embed UnimplementedMemberServer
 // for forward compatibility
@@ -235,11 +435,18 @@ type MemberServer interface {
 	HandleRequest(context.Context, *RequestMessage) (*MemberHandleResponse, error)
 	HandleNotify(context.Context, *NotifyMessage) (*MemberHandleResponse, error)
 	HandlePush(context.Context, *PushMessage) (*MemberHandleResponse, error)
+	HandlePushBatch(context.Context, *PushBatch) (*MemberHandleResponse, error)
 	HandleResponse(context.Context, *ResponseMessage) (*MemberHandleResponse, error)
+	HandleCall(context.Context, *CallRequest) (*CallResponse, error)
+	HandleMulticast(context.Context, *MulticastMessage) (*MemberHandleResponse, error)
+	Stream(Member_StreamServer) error
 	NewMember(context.Context, *NewMemberRequest) (*NewMemberResponse, error)
 	DelMember(context.Context, *DelMemberRequest) (*DelMemberResponse, error)
 	SessionClosed(context.Context, *SessionClosedRequest) (*SessionClosedResponse, error)
 	CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error)
+	FetchSession(context.Context, *FetchSessionRequest) (*FetchSessionResponse, error)
+	SyncSession(context.Context, *SyncSessionRequest) (*SyncSessionResponse, error)
+	Kick(context.Context, *KickRequest) (*KickResponse, error)
 }
 
 // UnimplementedMemberServer should be embedded to have forward compatible implementations.
@@ -255,9 +462,21 @@ func (UnimplementedMemberServer) HandleNotify(context.Context, *NotifyMessage) (
 func (UnimplementedMemberServer) HandlePush(context.Context, *PushMessage) (*MemberHandleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandlePush not implemented")
 }
+func (UnimplementedMemberServer) HandlePushBatch(context.Context, *PushBatch) (*MemberHandleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandlePushBatch not implemented")
+}
 func (UnimplementedMemberServer) HandleResponse(context.Context, *ResponseMessage) (*MemberHandleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleResponse not implemented")
 }
+func (UnimplementedMemberServer) HandleCall(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleCall not implemented")
+}
+func (UnimplementedMemberServer) HandleMulticast(context.Context, *MulticastMessage) (*MemberHandleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleMulticast not implemented")
+}
+func (UnimplementedMemberServer) Stream(Member_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
 func (UnimplementedMemberServer) NewMember(context.Context, *NewMemberRequest) (*NewMemberResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NewMember not implemented")
 }
@@ -270,6 +489,15 @@ func (UnimplementedMemberServer) SessionClosed(context.Context, *SessionClosedRe
 func (UnimplementedMemberServer) CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseSession not implemented")
 }
+func (UnimplementedMemberServer) FetchSession(context.Context, *FetchSessionRequest) (*FetchSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchSession not implemented")
+}
+func (UnimplementedMemberServer) SyncSession(context.Context, *SyncSessionRequest) (*SyncSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncSession not implemented")
+}
+func (UnimplementedMemberServer) Kick(context.Context, *KickRequest) (*KickResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Kick not implemented")
+}
 
 // UnsafeMemberServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to MemberServer will
@@ -336,6 +564,24 @@ func _Member_HandlePush_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Member_HandlePushBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandlePushBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandlePushBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandlePushBatch(ctx, req.(*PushBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Member_HandleResponse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ResponseMessage)
 	if err := dec(in); err != nil {
@@ -354,6 +600,68 @@ func _Member_HandleResponse_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Member_HandleCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandleCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandleCall",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandleCall(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_HandleMulticast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MulticastMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandleMulticast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandleMulticast",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandleMulticast(ctx, req.(*MulticastMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MemberServer).Stream(&memberStreamServer{stream})
+}
+
+type Member_StreamServer interface {
+	Send(*StreamMessage) error
+	Recv() (*StreamMessage, error)
+	grpc.ServerStream
+}
+
+type memberStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *memberStreamServer) Send(m *StreamMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *memberStreamServer) Recv() (*StreamMessage, error) {
+	m := new(StreamMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Member_NewMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NewMemberRequest)
 	if err := dec(in); err != nil {
@@ -426,6 +734,60 @@ func _Member_CloseSession_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Member_FetchSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).FetchSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/FetchSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).FetchSession(ctx, req.(*FetchSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_SyncSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).SyncSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/SyncSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).SyncSession(ctx, req.(*SyncSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_Kick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).Kick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/Kick",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).Kick(ctx, req.(*KickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Member_ServiceDesc is the grpc.ServiceDesc for Member service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -445,10 +807,22 @@ var Member_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HandlePush",
 			Handler:    _Member_HandlePush_Handler,
 		},
+		{
+			MethodName: "HandlePushBatch",
+			Handler:    _Member_HandlePushBatch_Handler,
+		},
 		{
 			MethodName: "HandleResponse",
 			Handler:    _Member_HandleResponse_Handler,
 		},
+		{
+			MethodName: "HandleCall",
+			Handler:    _Member_HandleCall_Handler,
+		},
+		{
+			MethodName: "HandleMulticast",
+			Handler:    _Member_HandleMulticast_Handler,
+		},
 		{
 			MethodName: "NewMember",
 			Handler:    _Member_NewMember_Handler,
@@ -465,7 +839,26 @@ var Member_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CloseSession",
 			Handler:    _Member_CloseSession_Handler,
 		},
+		{
+			MethodName: "FetchSession",
+			Handler:    _Member_FetchSession_Handler,
+		},
+		{
+			MethodName: "SyncSession",
+			Handler:    _Member_SyncSession_Handler,
+		},
+		{
+			MethodName: "Kick",
+			Handler:    _Member_Kick_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Member_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "cluster.proto",
 }
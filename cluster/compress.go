@@ -0,0 +1,78 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/revzim/amoeba/internal/codec"
+)
+
+// Packet compression algorithms could be negotiated with clients
+const (
+	CompressDeflate = codec.Deflate
+	CompressZstd    = codec.Zstd
+)
+
+// negotiateCompression selects the first compression algorithm of client which
+// enabled by current node, the negotiated parameters will be set into sys
+func (n *Node) negotiateCompression(req handshakeRequest, sys map[string]interface{}) (*codec.Compression, error) {
+	enabled := map[string]bool{}
+	for _, algo := range n.Compression {
+		enabled[algo] = true
+	}
+
+	for _, algo := range req.Sys.Compress {
+		if !enabled[algo] {
+			continue
+		}
+
+		// the shared dictionary is only supported by deflate
+		var dict []byte
+		if algo == codec.Deflate && req.Sys.CompressDict != "" &&
+			req.Sys.CompressDict == codec.DictChecksum(n.CompressDict) {
+			dict = n.CompressDict
+		}
+		c, err := codec.NewCompression(algo, n.CompressThreshold, dict, n.maxReassemblySize())
+		if err != nil {
+			return nil, err
+		}
+		sys["compress"] = algo
+		sys["compressThreshold"] = n.CompressThreshold
+		sys["compressDict"] = dict != nil
+		return c, nil
+	}
+	return nil, nil
+}
+
+func (n *Node) maxReassemblySize() int {
+	if n.MaxReassemblySize > 0 {
+		return n.MaxReassemblySize
+	}
+	return codec.DefaultMaxReassemblySize
+}
+
+func (n *Node) reassemblyTimeout() time.Duration {
+	if n.ReassemblyTimeout > 0 {
+		return n.ReassemblyTimeout
+	}
+	return codec.DefaultReassemblyTimeout
+}
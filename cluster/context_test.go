@@ -0,0 +1,124 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+)
+
+type (
+	ReportComponent  struct{ component.Base }
+	ArchiveComponent struct{ component.Base }
+)
+
+func (c *ReportComponent) Info(ctx context.Context, s *session.Session, _ *testdata.Ping) error {
+	r, ok := component.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no request in context")
+	}
+	_, deadline := ctx.Deadline()
+	return s.Response(&testdata.Pong{Content: fmt.Sprintf("%s %d %v %v", r.Route, r.ID, r.Metadata[component.TraceIDKey] != "", deadline)})
+}
+
+func (c *ReportComponent) Slow(ctx context.Context, s *session.Session, _ *testdata.Ping) error {
+	<-ctx.Done()
+	return s.Response(&testdata.Pong{Content: ctx.Err().Error()})
+}
+
+func (c *ArchiveComponent) Info(ctx context.Context, s *session.Session, _ *testdata.Ping) error {
+	r, _ := component.FromContext(ctx)
+	_, deadline := ctx.Deadline()
+	return s.Response(&testdata.Pong{Content: fmt.Sprintf("%s %v %v %d", r.Route, r.Metadata[component.TraceIDKey] != "", deadline, len(r.Metadata))})
+}
+
+func (s *nodeSuite) TestContextHandler(c *C) {
+	gateComps := &component.Components{}
+	gateComps.Register(&ReportComponent{}, component.WithTimeout(50*time.Millisecond))
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: gateComps,
+			ClientAddr: "127.0.0.1:27551",
+		},
+		ServiceAddr: "127.0.0.1:27550",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	archiveComps := &component.Components{}
+	archiveComps.Register(&ArchiveComponent{})
+	archive := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:27550",
+			Components:    archiveComps,
+		},
+		ServiceAddr: "127.0.0.1:27552",
+	}
+	c.Assert(archive.Startup(), IsNil)
+	defer archive.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:27551")
+	defer c1.Close()
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "ReportComponent.Info", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "ReportComponent.Info 1 true true")
+
+	c.Assert(c1.Call(context.Background(), "ReportComponent.Slow", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, context.DeadlineExceeded.Error())
+
+	// the remote handler takes the deadline of forwarded request
+	c.Assert(c1.Call(context.Background(), "ArchiveComponent.Info", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "ArchiveComponent.Info true true 1")
+}
+
+func (s *nodeSuite) TestStreamContext(c *C) {
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			Components:      &component.Components{},
+			ClientAddr:      "127.0.0.1:27561",
+			StreamTransport: true,
+		},
+		ServiceAddr: "127.0.0.1:27560",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	archiveComps := &component.Components{}
+	archiveComps.Register(&ArchiveComponent{})
+	archive := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:27560",
+			Components:      archiveComps,
+			StreamTransport: true,
+		},
+		ServiceAddr: "127.0.0.1:27562",
+	}
+	c.Assert(archive.Startup(), IsNil)
+	defer archive.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:27561")
+	defer c1.Close()
+
+	// the deadline of forwarded request is carried by the stream message
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "ArchiveComponent.Info", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "ArchiveComponent.Info true true 1")
+}
+
+func (s *nodeSuite) TestStreamDeadline(c *C) {
+	// the deadline is rebuilt from the remaining duration by the clock of
+	// receiver, rather than the absolute deadline of sender
+	before := time.Now()
+	deadline, ok := cluster.StreamDeadline(time.Second)
+	c.Assert(ok, IsTrue)
+	c.Assert(deadline.Before(before.Add(time.Second)), IsFalse)
+	c.Assert(deadline.After(time.Now().Add(time.Second)), IsFalse)
+}
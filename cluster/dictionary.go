@@ -0,0 +1,155 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/revzim/amoeba/internal/message"
+)
+
+// muDictionary serializes the updates of the route dictionary and lock file
+var muDictionary sync.Mutex
+
+// initDictionary generates the route dictionary from the local handlers and
+// the push routes, the codes will be sent to clients in handshake response.
+// The routes of remote members are added once they are known to current node,
+// see extendDictionary
+func (n *Node) initDictionary() error {
+	if !n.AutoDictionary {
+		return nil
+	}
+
+	routes := append([]string{}, n.PushRoutes...)
+	for route := range n.handler.localHandlers {
+		routes = append(routes, route)
+	}
+	return n.extendDictionary(routes)
+}
+
+// extendDictionary assigns codes to the routes which are not in the route
+// dictionary, the clients connected later will receive the new codes, and the
+// connected clients send and receive the new routes uncompressed, because each
+// agent keeps encoding with the dictionary sent in its handshake. Each node
+// assigns codes on its own, so the nodes accepting clients should share one
+// lock file, which keeps the codes same across them
+func (n *Node) extendDictionary(routes []string) error {
+	if !n.AutoDictionary || len(routes) == 0 {
+		return nil
+	}
+	muDictionary.Lock()
+	defer muDictionary.Unlock()
+
+	existing := message.Routes()
+	dict, err := buildDictionary(existing, routes, n.DictionaryLock)
+	if err != nil {
+		return err
+	}
+
+	// only the new routes should be set, the existing routes are unchanged
+	for route := range existing {
+		delete(dict, route)
+	}
+	if len(dict) == 0 {
+		return nil
+	}
+	message.SetDictionary(dict)
+	return cacheHandshake()
+}
+
+// buildDictionary assigns codes to the routes which not in dict, the codes
+// recorded in lock file will be reused, so the codes are stable across restarts.
+// The lock file will be rewritten if there are new codes assigned
+func buildDictionary(dict map[string]uint16, routes []string, lockFile string) (map[string]uint16, error) {
+	result := make(map[string]uint16, len(dict)+len(routes))
+	used := map[uint16]bool{}
+	for route, code := range dict {
+		result[route] = code
+		used[code] = true
+	}
+
+	locked := map[string]uint16{}
+	if lockFile != "" {
+		data, err := ioutil.ReadFile(lockFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &locked); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the codes set explicitly take precedence over the locked ones
+	changed := false
+	keys := make([]string, 0, len(locked))
+	for route := range locked {
+		keys = append(keys, route)
+	}
+	sort.Strings(keys)
+	for _, route := range keys {
+		code := locked[route]
+		if c, found := result[route]; found {
+			changed = changed || c != code
+			continue
+		}
+		if used[code] {
+			changed = true
+			continue
+		}
+		result[route] = code
+		used[code] = true
+	}
+
+	sort.Strings(routes)
+	next := uint16(1)
+	for _, route := range routes {
+		if _, found := result[route]; found {
+			continue
+		}
+		for used[next] {
+			if next == math.MaxUint16 {
+				return nil, ErrDictionaryExhaust
+			}
+			next++
+		}
+		result[route] = next
+		used[next] = true
+		changed = true
+	}
+
+	if lockFile != "" && changed {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(lockFile, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package cluster_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/session"
+)
+
+func (s *nodeSuite) TestBuildDictionary(c *C) {
+	lock := filepath.Join(c.MkDir(), "dict.lock")
+
+	dict, err := cluster.BuildDictionary(map[string]uint16{"Room.Join": 1}, []string{"Room.Leave", "Room.Join", "Room.Chat"}, lock)
+	c.Assert(err, IsNil)
+	c.Assert(dict, DeepEquals, map[string]uint16{"Room.Join": 1, "Room.Chat": 2, "Room.Leave": 3})
+
+	// the codes are kept after the routes changed
+	dict, err = cluster.BuildDictionary(nil, []string{"Room.Leave", "Room.Kick"}, lock)
+	c.Assert(err, IsNil)
+	c.Assert(dict, DeepEquals, map[string]uint16{"Room.Join": 1, "Room.Chat": 2, "Room.Leave": 3, "Room.Kick": 4})
+
+	data, err := ioutil.ReadFile(lock)
+	c.Assert(err, IsNil)
+	locked := map[string]uint16{}
+	c.Assert(json.Unmarshal(data, &locked), IsNil)
+	c.Assert(locked, DeepEquals, dict)
+
+	// the codes set explicitly take precedence
+	dict, err = cluster.BuildDictionary(map[string]uint16{"Room.Kick": 2}, nil, lock)
+	c.Assert(err, IsNil)
+	c.Assert(dict, DeepEquals, map[string]uint16{"Room.Join": 1, "Room.Kick": 2, "Room.Leave": 3})
+}
+
+func (s *nodeSuite) TestAutoDictionary(c *C) {
+	comps := &component.Components{}
+	comps.Register(&LoungeComponent{sessions: map[int64]*session.Session{}})
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:       true,
+			Components:     comps,
+			ClientAddr:     "127.0.0.1:22551",
+			AutoDictionary: true,
+			DictionaryLock: filepath.Join(c.MkDir(), "dict.lock"),
+			PushRoutes:     []string{"LoungeComponent.Kicked"},
+		},
+		ServiceAddr: "127.0.0.1:22550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:22551")
+	defer c1.Close()
+
+	dict := c1.Dictionary()
+	for _, route := range []string{"LoungeComponent.Join", "LoungeComponent.Whereami", "LoungeComponent.Kicked"} {
+		_, found := dict[route]
+		c.Assert(found, IsTrue, Commentf("route %s", route))
+	}
+
+	// the client with cached dictionary does not download it again
+	conn, err := net.Dial("tcp", "127.0.0.1:22551")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	hs, err := json.Marshal(map[string]interface{}{
+		"sys": map[string]interface{}{"dictHash": message.DictionaryHash(dict)},
+	})
+	c.Assert(err, IsNil)
+	p, err := codec.Encode(packet.Handshake, hs)
+	c.Assert(err, IsNil)
+	_, err = conn.Write(p)
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	c.Assert(err, IsNil)
+	packets, err := codec.NewDecoder().Decode(buf[:n])
+	c.Assert(err, IsNil)
+	c.Assert(packets, HasLen, 1)
+	res := struct {
+		Sys map[string]interface{} `json:"sys"`
+	}{}
+	c.Assert(json.Unmarshal(packets[0].Data, &res), IsNil)
+	_, found := res.Sys["dict"]
+	c.Assert(found, IsFalse)
+	c.Assert(res.Sys["dictHash"], Equals, message.DictionaryHash(dict))
+
+	c2, err := client.Dial("127.0.0.1:22551", client.WithDictionary(dict))
+	c.Assert(err, IsNil)
+	defer c2.Close()
+	pong := &testdata.Pong{}
+	c.Assert(c2.Call(context.Background(), "LoungeComponent.Join", &testdata.Ping{Content: "dictionary"}, pong), IsNil)
+	c.Assert(c2.Dictionary(), DeepEquals, dict)
+
+	// the stale cached dictionary is replaced rather than merged
+	stale := map[string]uint16{"LoungeComponent.Removed": dict["LoungeComponent.Whereami"]}
+	c3, err := client.Dial("127.0.0.1:22551", client.WithDictionary(stale))
+	c.Assert(err, IsNil)
+	defer c3.Close()
+	c.Assert(c3.Dictionary(), DeepEquals, dict)
+}
+
+type AtlasComponent struct{ component.Base }
+
+func (c *AtlasComponent) Locate(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	return &testdata.Pong{Content: "located " + ping.Content}, nil
+}
+
+// Beacon pushes to the route of itself before responding
+func (c *AtlasComponent) Beacon(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	if err := s.Push("AtlasComponent.Beacon", &testdata.Pong{Content: "beacon " + ping.Content}); err != nil {
+		return nil, err
+	}
+	return &testdata.Pong{Content: "located " + ping.Content}, nil
+}
+
+func (s *nodeSuite) TestAutoDictionaryRemoteRoutes(c *C) {
+	lock := filepath.Join(c.MkDir(), "dict.lock")
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:       true,
+			Components:     &component.Components{},
+			ClientAddr:     "127.0.0.1:22553",
+			AutoDictionary: true,
+			DictionaryLock: lock,
+		},
+		ServiceAddr: "127.0.0.1:22552",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	// the client connected before the routes known keeps its dictionary
+	c0 := dial(c, "127.0.0.1:22553")
+	defer c0.Close()
+	chBeacon := make(chan string, 1)
+	c.Assert(c0.On("AtlasComponent.Beacon", func(pong *testdata.Pong) {
+		chBeacon <- pong.Content
+	}), IsNil)
+
+	comps := &component.Components{}
+	comps.Register(&AtlasComponent{})
+	backend := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:22552",
+			Components:    comps,
+		},
+		ServiceAddr: "127.0.0.1:22554",
+	}
+	c.Assert(backend.Startup(), IsNil)
+	defer backend.Shutdown()
+
+	// the routes of backend are shipped by gate which hosts none of them
+	c1 := dial(c, "127.0.0.1:22553")
+	defer c1.Close()
+	_, found := c1.Dictionary()["AtlasComponent.Locate"]
+	c.Assert(found, IsTrue)
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "AtlasComponent.Locate", &testdata.Ping{Content: "gate"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "located gate")
+
+	data, err := ioutil.ReadFile(lock)
+	c.Assert(err, IsNil)
+	locked := map[string]uint16{}
+	c.Assert(json.Unmarshal(data, &locked), IsNil)
+	c.Assert(locked["AtlasComponent.Locate"], Equals, c1.Dictionary()["AtlasComponent.Locate"])
+
+	// the new routes are sent to the client connected earlier uncompressed
+	_, found = c0.Dictionary()["AtlasComponent.Beacon"]
+	c.Assert(found, IsFalse)
+	c.Assert(c0.Call(context.Background(), "AtlasComponent.Beacon", &testdata.Ping{Content: "early"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "located early")
+	select {
+	case content := <-chBeacon:
+		c.Assert(content, Equals, "beacon early")
+	case <-time.After(time.Second):
+		c.Fatal("push timeout")
+	}
+}
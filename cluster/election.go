@@ -0,0 +1,455 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The master nodes elect a leader with a simplified raft protocol: every master
+// starts as follower, and starts an election after it has not heard from the
+// leader in a randomized election timeout. The candidate which collects votes
+// from the majority becomes the leader of the term. There is no log in the
+// replication, the leader sends the full member list(a snapshot) to followers
+// in every heartbeat, so the followers can take over the registry at any time.
+// The leader steps down if the majority have not acknowledged it in an election
+// timeout, so the leader isolated from the quorum stops accepting registrations.
+
+const defaultElectionTimeout = 3 * time.Second
+
+// initElection initializes the replication states of current master node, the
+// election loop will be started if there are more than one master node
+func (c *cluster) initElection(masters []string) {
+	self := c.currentNode.ServiceAddr
+	found := false
+	for _, addr := range masters {
+		if addr == self {
+			found = true
+			break
+		}
+	}
+	if !found {
+		masters = append([]string{self}, masters...)
+	}
+
+	c.Lock()
+	c.masters = masters
+	c.lastContact = time.Now()
+	c.resetTimeout()
+	if len(masters) == 1 {
+		// Standalone master is always the leader
+		c.term = 1
+		c.leader = self
+	}
+	c.Unlock()
+
+	if len(masters) > 1 {
+		go c.elect()
+	}
+}
+
+func (c *cluster) electionTimeout() time.Duration {
+	if d := c.currentNode.ElectionTimeout; d > 0 {
+		return d
+	}
+	return defaultElectionTimeout
+}
+
+// resetTimeout randomizes the election timeout in [timeout, 2*timeout) to
+// avoid split votes, must be called with lock held
+func (c *cluster) resetTimeout() {
+	d := c.electionTimeout()
+	c.timeout = d + time.Duration(rand.Int63n(int64(d)))
+}
+
+func (c *cluster) elect() {
+	ticker := time.NewTicker(c.electionTimeout() / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if c.isLeader() && c.checkQuorum() {
+				c.replicate()
+				continue
+			}
+			c.RLock()
+			expired := time.Since(c.lastContact) > c.timeout
+			c.RUnlock()
+			if expired {
+				c.campaign()
+			}
+
+		case <-c.chDie:
+			return
+		}
+	}
+}
+
+func (c *cluster) stopElection() {
+	select {
+	case <-c.chDie:
+	default:
+		close(c.chDie)
+	}
+}
+
+func (c *cluster) isMasterAddr(addr string) bool {
+	for _, m := range c.masters {
+		if m == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cluster) isLeader() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.leader == c.currentNode.ServiceAddr
+}
+
+func (c *cluster) leaderAddr() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.leader
+}
+
+func (c *cluster) leaderClient() (clusterpb.MasterClient, error) {
+	leader := c.leaderAddr()
+	if leader == "" {
+		return nil, status.Error(codes.Unavailable, "no leader elected in cluster")
+	}
+	pool, err := c.rpcClient.getConnPool(leader)
+	if err != nil {
+		return nil, err
+	}
+	return clusterpb.NewMasterClient(pool.Get()), nil
+}
+
+// peers returns the addresses of other master nodes, must be called with lock held
+func (c *cluster) peers() []string {
+	var peers []string
+	for _, addr := range c.masters {
+		if addr != c.currentNode.ServiceAddr {
+			peers = append(peers, addr)
+		}
+	}
+	return peers
+}
+
+// checkQuorum reports whether the majority of masters acknowledged current
+// leader within the election timeout, the leader steps down otherwise
+func (c *cluster) checkQuorum() bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.leader != c.currentNode.ServiceAddr || time.Since(c.lastContact) <= c.electionTimeout() {
+		return true
+	}
+	log.Println("Leader steps down without quorum", c.leader, "term", c.term)
+	c.leader = ""
+	c.lastContact = time.Now()
+	c.resetTimeout()
+	return false
+}
+
+// ack records the follower acknowledged current leader of term
+func (c *cluster) ack(addr string, term uint64) {
+	c.Lock()
+	defer c.Unlock()
+	if c.term != term || c.leader != c.currentNode.ServiceAddr {
+		return
+	}
+	now := time.Now()
+	c.acks[addr] = now
+	count := 1
+	for _, at := range c.acks {
+		if now.Sub(at) <= c.electionTimeout() {
+			count++
+		}
+	}
+	if count >= len(c.masters)/2+1 {
+		c.lastContact = now
+	}
+}
+
+// stepDown converts current node to follower when a higher term discovered
+func (c *cluster) stepDown(term uint64) {
+	c.Lock()
+	if term > c.term {
+		c.term = term
+		c.votedFor = ""
+		c.leader = ""
+		c.lastContact = time.Now()
+		c.resetTimeout()
+	}
+	c.Unlock()
+}
+
+func (c *cluster) campaign() {
+	self := c.currentNode.ServiceAddr
+	c.Lock()
+	c.term++
+	c.votedFor = self
+	c.leader = ""
+	c.lastContact = time.Now()
+	c.resetTimeout()
+	term, version, peers, quorum := c.term, c.version, c.peers(), len(c.masters)/2+1
+	c.Unlock()
+
+	log.Println("Start leader election", self, "term", term)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.electionTimeout()/2)
+	defer cancel()
+
+	chVote := make(chan *clusterpb.RequestVoteResponse, len(peers))
+	request := &clusterpb.RequestVoteRequest{Term: term, Candidate: self, Version: version}
+	for _, addr := range peers {
+		go func(addr string) {
+			pool, err := c.rpcClient.getConnPool(addr)
+			if err != nil {
+				chVote <- nil
+				return
+			}
+			resp, err := clusterpb.NewMasterClient(pool.Get()).RequestVote(ctx, request)
+			if err != nil {
+				chVote <- nil
+				return
+			}
+			chVote <- resp
+		}(addr)
+	}
+
+	votes := 1
+	for range peers {
+		resp := <-chVote
+		if resp == nil {
+			continue
+		}
+		if resp.Term > term {
+			c.stepDown(resp.Term)
+			return
+		}
+		if resp.Granted {
+			votes++
+		}
+	}
+
+	if votes < quorum {
+		return
+	}
+
+	c.Lock()
+	if c.term != term || c.votedFor != self {
+		c.Unlock()
+		return
+	}
+	c.leader = self
+	c.acks = map[string]time.Time{}
+	c.Unlock()
+
+	log.Println("Current node becomes leader", self, "term", term)
+	c.registerLeader()
+	c.replicate()
+}
+
+// registerLeader ensures the new leader itself is contained in the member list
+func (c *cluster) registerLeader() {
+	node := c.currentNode
+	c.RLock()
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == node.ServiceAddr {
+			c.RUnlock()
+			return
+		}
+	}
+	c.RUnlock()
+
+	info := node.memberInfo()
+	if _, err := c.Register(context.Background(), &clusterpb.RegisterRequest{MemberInfo: info}); err != nil {
+		log.Println("Register leader to cluster failed", err)
+	}
+}
+
+// registerFollower registers current master to the leader, which is used when a
+// follower found itself not in the member list of leader
+func (c *cluster) registerFollower() {
+	if !atomic.CompareAndSwapInt32(&c.registering, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&c.registering, 0)
+
+	client, err := c.leaderClient()
+	if err != nil {
+		return
+	}
+	node := c.currentNode
+	request := &clusterpb.RegisterRequest{
+		MemberInfo: node.memberInfo(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.electionTimeout())
+	defer cancel()
+	if _, err := client.Register(ctx, request); err != nil {
+		log.Println("Register current master to leader failed", err)
+	}
+}
+
+// replicate sends the member list snapshot to all followers, it does nothing
+// if current node is not the leader
+func (c *cluster) replicate() {
+	c.RLock()
+	if c.leader != c.currentNode.ServiceAddr || len(c.masters) < 2 {
+		c.RUnlock()
+		return
+	}
+	request := &clusterpb.AppendMembersRequest{
+		Term:    c.term,
+		Leader:  c.leader,
+		Version: c.version,
+	}
+	for _, m := range c.members {
+		request.Members = append(request.Members, m.memberInfo)
+	}
+	peers := c.peers()
+	c.RUnlock()
+
+	for _, addr := range peers {
+		go func(addr string) {
+			pool, err := c.rpcClient.getConnPool(addr)
+			if err != nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), c.electionTimeout()/2)
+			defer cancel()
+			resp, err := clusterpb.NewMasterClient(pool.Get()).AppendMembers(ctx, request)
+			if err != nil {
+				return
+			}
+			if resp.Term > request.Term {
+				c.stepDown(resp.Term)
+				return
+			}
+			if resp.Success {
+				c.ack(addr, request.Term)
+			}
+		}(addr)
+	}
+}
+
+// RequestVote implements the MasterServer gRPC service
+func (c *cluster) RequestVote(_ context.Context, req *clusterpb.RequestVoteRequest) (*clusterpb.RequestVoteResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if req.Term < c.term {
+		return &clusterpb.RequestVoteResponse{Term: c.term}, nil
+	}
+	if req.Term > c.term {
+		c.term = req.Term
+		c.votedFor = ""
+		c.leader = ""
+	}
+
+	// Only vote for the candidate whose member list is at least as new as ours
+	granted := (c.votedFor == "" || c.votedFor == req.Candidate) && req.Version >= c.version
+	if granted {
+		c.votedFor = req.Candidate
+		c.lastContact = time.Now()
+		c.resetTimeout()
+	}
+	return &clusterpb.RequestVoteResponse{Term: c.term, Granted: granted}, nil
+}
+
+// AppendMembers implements the MasterServer gRPC service
+func (c *cluster) AppendMembers(_ context.Context, req *clusterpb.AppendMembersRequest) (*clusterpb.AppendMembersResponse, error) {
+	self := c.currentNode.ServiceAddr
+
+	c.Lock()
+	if req.Term < c.term {
+		term := c.term
+		c.Unlock()
+		return &clusterpb.AppendMembersResponse{Term: term}, nil
+	}
+	if req.Term > c.term {
+		c.term = req.Term
+		c.votedFor = ""
+	}
+	c.leader = req.Leader
+	c.lastContact = time.Now()
+	c.resetTimeout()
+
+	// The versions are increased by each leader independently, so the list of
+	// a new leader may have the same version as the one applied
+	var added []*clusterpb.MemberInfo
+	var removed []string
+	if req.Term != c.memberTerm || req.Version != c.version {
+		existing := map[string]bool{}
+		for _, m := range c.members {
+			existing[m.memberInfo.ServiceAddr] = true
+		}
+		members := make([]*Member, 0, len(req.Members))
+		for _, info := range req.Members {
+			members = append(members, &Member{isMaster: c.isMasterAddr(info.ServiceAddr), memberInfo: info})
+			if !existing[info.ServiceAddr] && info.ServiceAddr != self {
+				added = append(added, info)
+			}
+			delete(existing, info.ServiceAddr)
+		}
+		for addr := range existing {
+			if addr != self {
+				removed = append(removed, addr)
+			}
+		}
+		c.members = members
+		c.version = req.Version
+		c.memberTerm = req.Term
+	}
+	c.Unlock()
+
+	registered := false
+	for _, info := range req.Members {
+		if info.ServiceAddr == self {
+			registered = true
+			break
+		}
+	}
+
+	node := c.currentNode
+	for _, addr := range removed {
+		node.delMember(addr)
+	}
+	for _, info := range added {
+		node.handler.addRemoteService(info)
+	}
+	if !registered {
+		go c.registerFollower()
+	}
+
+	return &clusterpb.AppendMembersResponse{Term: req.Term, Success: true}, nil
+}
@@ -0,0 +1,234 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/component"
+	"google.golang.org/grpc"
+)
+
+func waitLeader(c *C, nodes []*cluster.Node) string {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		leader := nodes[0].Leader()
+		agreed, alive := true, false
+		for _, node := range nodes {
+			if node.Leader() != leader {
+				agreed = false
+			}
+			if node.ServiceAddr == leader {
+				alive = true
+			}
+		}
+		if agreed && alive {
+			return leader
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Fatal("no leader elected")
+	return ""
+}
+
+func waitRemoteService(c *C, nodes []*cluster.Node, service string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		found := 0
+		for _, node := range nodes {
+			for _, s := range node.Handler().RemoteService() {
+				if s == service {
+					found++
+					break
+				}
+			}
+		}
+		if found == len(nodes) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Fatalf("service %s not replicated", service)
+}
+
+func (s *nodeSuite) TestMasterFailover(c *C) {
+	masters := []string{"127.0.0.1:14550", "127.0.0.1:14551", "127.0.0.1:14552"}
+	var nodes []*cluster.Node
+	for _, addr := range masters {
+		comps := &component.Components{}
+		comps.Register(&MasterComponent{})
+		node := &cluster.Node{
+			Options: cluster.Options{
+				IsMaster:        true,
+				Masters:         masters,
+				ElectionTimeout: 200 * time.Millisecond,
+				Components:      comps,
+			},
+			ServiceAddr: addr,
+		}
+		c.Assert(node.Startup(), IsNil)
+		nodes = append(nodes, node)
+	}
+	leader := waitLeader(c, nodes)
+
+	member1Comps := &component.Components{}
+	member1Comps.Register(&GameComponent{})
+	member1 := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: masters[0],
+			Masters:       masters,
+			RetryInterval: 100 * time.Millisecond,
+			Components:    member1Comps,
+		},
+		ServiceAddr: "127.0.0.1:14560",
+	}
+	c.Assert(member1.Startup(), IsNil)
+	waitRemoteService(c, nodes, "GameComponent")
+
+	// Stop the leader and the remaining masters should take over
+	var remaining []*cluster.Node
+	for _, node := range nodes {
+		if node.ServiceAddr == leader {
+			node.Shutdown()
+		} else {
+			remaining = append(remaining, node)
+		}
+	}
+	newLeader := waitLeader(c, remaining)
+	c.Assert(newLeader, Not(Equals), leader)
+
+	// Registering to the dead leader will be retried on other masters
+	member2Comps := &component.Components{}
+	member2Comps.Register(&GateComponent{})
+	member2 := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: leader,
+			Masters:       masters,
+			RetryInterval: 100 * time.Millisecond,
+			Components:    member2Comps,
+		},
+		ServiceAddr: "127.0.0.1:14561",
+	}
+	c.Assert(member2.Startup(), IsNil)
+	waitRemoteService(c, remaining, "GateComponent")
+	waitRemoteService(c, remaining, "GameComponent")
+	waitRemoteService(c, []*cluster.Node{member1}, "GateComponent")
+
+	member2.Shutdown()
+	member1.Shutdown()
+	for _, node := range remaining {
+		node.Shutdown()
+	}
+}
+
+func (s *nodeSuite) TestLeaderStepDown(c *C) {
+	masters := []string{"127.0.0.1:14580", "127.0.0.1:14581", "127.0.0.1:14582"}
+	var nodes []*cluster.Node
+	for _, addr := range masters {
+		node := &cluster.Node{
+			Options: cluster.Options{
+				IsMaster:        true,
+				Masters:         masters,
+				ElectionTimeout: 200 * time.Millisecond,
+				Components:      &component.Components{},
+			},
+			ServiceAddr: addr,
+		}
+		c.Assert(node.Startup(), IsNil)
+		nodes = append(nodes, node)
+	}
+	leader := waitLeader(c, nodes)
+
+	// The followers crash, and the leader steps down without quorum
+	var isolated *cluster.Node
+	for _, node := range nodes {
+		if node.ServiceAddr == leader {
+			isolated = node
+		} else {
+			node.Halt()
+		}
+	}
+	defer isolated.Shutdown()
+	retry(c, func() error {
+		if isolated.Leader() == leader {
+			return fmt.Errorf("leader %s not stepped down", leader)
+		}
+		return nil
+	})
+
+	conn, err := grpc.Dial(leader, grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = clusterpb.NewMasterClient(conn).Register(context.Background(), &clusterpb.RegisterRequest{
+		MemberInfo: &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:14583"},
+	})
+	c.Assert(err, NotNil)
+}
+
+func (s *nodeSuite) TestRegisterConcurrently(c *C) {
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: &component.Components{}},
+		ServiceAddr: "127.0.0.1:14584",
+	}
+	c.Assert(master.Startup(), IsNil)
+	defer master.Shutdown()
+
+	comps := &component.Components{}
+	comps.Register(&GameComponent{})
+	game := &cluster.Node{
+		Options:     cluster.Options{AdvertiseAddr: "127.0.0.1:14584", Components: comps},
+		ServiceAddr: "127.0.0.1:14585",
+	}
+	c.Assert(game.Startup(), IsNil)
+	defer game.Shutdown()
+
+	conn, err := grpc.Dial("127.0.0.1:14584", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+
+	// Only one of the registrations of the same address succeeds
+	const n = 8
+	chErr := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := clusterpb.NewMasterClient(conn).Register(context.Background(), &clusterpb.RegisterRequest{
+				MemberInfo: &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:14586", Services: []string{"GateComponent"}},
+			})
+			chErr <- err
+		}()
+	}
+	registered := 0
+	for i := 0; i < n; i++ {
+		if <-chErr == nil {
+			registered++
+		}
+	}
+	c.Assert(registered, Equals, 1)
+	c.Assert(master.Handler().RemoteService(), DeepEquals, []string{"GameComponent", "GateComponent"})
+}
+
+func (s *nodeSuite) TestAppendMembersOfNewLeader(c *C) {
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: &component.Components{},
+		},
+		ServiceAddr: "127.0.0.1:14570",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	self := &clusterpb.MemberInfo{ServiceAddr: node.ServiceAddr}
+	game := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:14571", Services: []string{"GameComponent"}}
+	gate := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:14572", Services: []string{"GateComponent"}}
+	c.Assert(node.AppendMembers(2, 5, self, game), IsNil)
+	c.Assert(node.Handler().RemoteService(), DeepEquals, []string{"GameComponent"})
+
+	// the leader of new term counts its versions independently, the list is
+	// applied even if the version equals to the applied one
+	c.Assert(node.AppendMembers(3, 5, self, gate), IsNil)
+	c.Assert(node.Handler().RemoteService(), DeepEquals, []string{"GateComponent"})
+}
@@ -24,7 +24,21 @@ import "errors"
 
 // Errors that could be occurred during message handling.
 var (
-	ErrSessionOnNotify    = errors.New("current session working on notify mode")
-	ErrCloseClosedSession = errors.New("close closed session")
-	ErrInvalidRegisterReq = errors.New("invalid register request")
+	ErrSessionOnNotify     = errors.New("current session working on notify mode")
+	ErrCloseClosedSession  = errors.New("close closed session")
+	ErrInvalidRegisterReq  = errors.New("invalid register request")
+	ErrInvalidRoute        = errors.New("invalid route")
+	ErrRouteNotFound       = errors.New("route not found in cluster")
+	ErrSessionNoGate       = errors.New("session is not bound to any gate")
+	ErrStreamClosed        = errors.New("member stream closed")
+	ErrBatcherClosed       = errors.New("push batcher closed")
+	ErrBatcherBackpressure = errors.New("push batcher backlog exceed")
+	ErrStreamBackpressure  = errors.New("member stream send backlog exceed")
+	ErrInvalidStream       = errors.New("member stream without service address")
+	ErrDictionaryExhaust   = errors.New("route dictionary codes exhausted")
+	ErrClientOutdated      = errors.New("client version outdated")
+	ErrRateLimited         = errors.New("rate limit exceeded")
+	ErrInvalidNonce        = errors.New("invalid handshake nonce")
+	ErrKeyRequired         = errors.New("key exchange required by connection stages")
+	ErrNonceRequired       = errors.New("handshake nonce required by connection stages")
 )
@@ -0,0 +1,106 @@
+package cluster_test
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/errors"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/session"
+)
+
+var errInsufficientGold = errors.New(1001, "insufficient gold")
+
+type (
+	ShopComponent struct{ component.Base }
+	BankComponent struct{ component.Base }
+)
+
+func (c *ShopComponent) Buy(s *session.Session, ping *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: ping.Content})
+}
+
+func (c *ShopComponent) Crash(s *session.Session, _ *testdata.Ping) error {
+	return stderrors.New("boom")
+}
+
+func (c *BankComponent) Withdraw(s *session.Session, _ *testdata.Ping) error {
+	return errInsufficientGold.WithDetails(map[string]interface{}{"balance": float64(10)})
+}
+
+func (s *nodeSuite) TestErrorResponse(c *C) {
+	pipe := pipeline.New()
+	pipe.Inbound().PushBack(func(_ *session.Session, msg *message.Message) error {
+		if msg.Route == "ShopComponent.Buy" && strings.Contains(string(msg.Data), "banned") {
+			return stderrors.New("rejected")
+		}
+		return nil
+	})
+	gateComps := &component.Components{}
+	gateComps.Register(&ShopComponent{})
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Pipeline:   pipe,
+			Components: gateComps,
+			ClientAddr: "127.0.0.1:26551",
+		},
+		ServiceAddr: "127.0.0.1:26550",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	bankComps := &component.Components{}
+	bankComps.Register(&BankComponent{})
+	bank := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:26550",
+			Components:    bankComps,
+		},
+		ServiceAddr: "127.0.0.1:26552",
+	}
+	c.Assert(bank.Startup(), IsNil)
+	defer bank.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:26551")
+	defer c1.Close()
+
+	call := func(route string, v interface{}) *errors.Error {
+		err := c1.Call(context.Background(), route, v, &testdata.Pong{})
+		e, ok := err.(*errors.Error)
+		c.Assert(ok, IsTrue, Commentf("route %s: %v", route, err))
+		return e
+	}
+
+	// the custom error returned by remote handler
+	e := call("BankComponent.Withdraw", &testdata.Ping{})
+	c.Assert(stderrors.Is(e, errInsufficientGold), IsTrue)
+	c.Assert(e.Message, Equals, "insufficient gold")
+	c.Assert(e.Details["balance"], Equals, float64(10))
+
+	// the text of unexpected error is not exposed to client
+	e = call("ShopComponent.Crash", &testdata.Ping{})
+	c.Assert(e.Code, Equals, errors.CodeInternal)
+	c.Assert(e.Message, Equals, "internal error")
+	c.Assert(call("ShopComponent.Buy", []byte("{")).Code, Equals, errors.CodeBadRequest)
+	c.Assert(call("ShopComponent.Buy", &testdata.Ping{Content: "banned"}).Code, Equals, errors.CodeRejected)
+	c.Assert(call("MissingComponent.Get", &testdata.Ping{}).Code, Equals, errors.CodeNotFound)
+
+	// the session still works after the failed requests
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "ShopComponent.Buy", &testdata.Ping{Content: "sword"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "sword")
+
+	// the error of remote call between nodes keeps its code and details
+	err := gate.Call(context.Background(), "BankComponent.Withdraw", &testdata.Ping{}, pong)
+	e, ok := err.(*errors.Error)
+	c.Assert(ok, IsTrue, Commentf("%v", err))
+	c.Assert(stderrors.Is(e, errInsufficientGold), IsTrue)
+	c.Assert(e.Details["balance"], Equals, float64(10))
+}
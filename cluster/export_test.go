@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/session"
+)
+
+// Halt stops current node without unregistering from cluster, which is
+// used to simulate a crashed node
+func (n *Node) Halt() {
+	close(n.chDie)
+	n.cluster.stopElection()
+	n.server.Stop()
+}
+
+func (n *Node) DetachedSessions() int {
+	n.resumer.Lock()
+	defer n.resumer.Unlock()
+	return len(n.resumer.parked)
+}
+
+// SyncStale applies the values synchronized by the backend of addr with the
+// stale uid to the session of gate
+func (n *Node) SyncStale(addr string, sid, uid int64, values map[string]interface{}) error {
+	data, err := encodeState(session.Delta{Values: values})
+	if err != nil {
+		return err
+	}
+	_, err = n.SyncSession(context.Background(), &clusterpb.SyncSessionRequest{
+		SessionId:   sid,
+		ServiceAddr: addr,
+		Uid:         uid,
+		Data:        data,
+	})
+	return err
+}
+
+// StreamDeadline returns the deadline of the message forwarded over member
+// stream with the timeout
+func StreamDeadline(timeout time.Duration) (time.Time, bool) {
+	md := map[string]string{}
+	setStreamTimeout(md, timeout)
+	ctx, cancel := streamContext(md)
+	defer cancel()
+	return ctx.Deadline()
+}
+
+// PendingSerializers returns the number of serializers recorded for the
+// requests not responded yet
+func (n *Node) PendingSerializers() int {
+	n.RLock()
+	defer n.RUnlock()
+	count := 0
+	for _, s := range n.sessions {
+		if p := serializersOf(s); p != nil {
+			p.mu.Lock()
+			count += len(p.m)
+			p.mu.Unlock()
+		}
+	}
+	return count
+}
+
+// DeliverAfterClose queues a delivery to the agent whose send queue is full,
+// and closes the agent while the delivery is pending. It returns once the
+// delivery goroutine exited
+func DeliverAfterClose() {
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	a := newAgent(conn, nil, nil, nil, nil)
+	for i := 0; i < agentWriteBacklog; i++ {
+		a.chSend <- pendingMessage{}
+	}
+	a.deliver(pendingMessage{})
+	time.Sleep(10 * time.Millisecond)
+	a.close(true)
+	for {
+		a.muDeliver.Lock()
+		delivering := a.delivering
+		a.muDeliver.Unlock()
+		if !delivering {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+var BuildDictionary = buildDictionary
+
+var CompareVersion = compareVersion
+
+// AppendMembers applies the member list snapshot sent by the leader of term
+func (n *Node) AppendMembers(term, version uint64, members ...*clusterpb.MemberInfo) error {
+	_, err := n.cluster.AppendMembers(context.Background(), &clusterpb.AppendMembersRequest{
+		Term:    term,
+		Leader:  "127.0.0.1:0",
+		Version: version,
+		Members: members,
+	})
+	return err
+}
+
+// PushFailures returns the number of sessions which failures of queued messages
+// are not taken yet
+func (n *Node) PushFailures() int {
+	n.Lock()
+	defer n.Unlock()
+	count := 0
+	for _, b := range n.pushers {
+		b.Lock()
+		count += len(b.failed)
+		b.Unlock()
+	}
+	return count
+}
+
+// PushBacklog is the max number of messages queued to a gate
+const PushBacklog = pushBacklog
+
+// StreamAddrKey is the metadata key of the peer address of member stream
+const StreamAddrKey = streamAddrKey
@@ -24,57 +24,149 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"net"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/revzim/amoeba/balancer"
 	"github.com/revzim/amoeba/cluster/clusterpb"
 	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/errors"
 	"github.com/revzim/amoeba/internal/codec"
 	"github.com/revzim/amoeba/internal/env"
 	"github.com/revzim/amoeba/internal/log"
 	"github.com/revzim/amoeba/internal/message"
 	"github.com/revzim/amoeba/internal/packet"
 	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/ratelimit"
 	"github.com/revzim/amoeba/scheduler"
 	"github.com/revzim/amoeba/session"
 )
 
 const (
 	DefaultWSClientCloseMsg = "websocket: close 1000 (normal)"
+
+	// metadata key which lists the inbound stages processed by gate for the
+	// forwarded message, so the remote member will not process them again
+	inboundProcessedKey = "amoeba-inbound-processed"
 )
 
 var (
 	// cached serialized data
-	hrd []byte // handshake response data
 	hbd []byte // heartbeat packet data
+
+	// *handshakeCache of current route dictionary, it is replaced once the
+	// dictionary extended with the routes of remote members
+	handshakes atomic.Value
 )
 
-type rpcHandler func(session *session.Session, msg *message.Message, noCopy bool)
+type (
+	rpcHandler func(session *session.Session, msg *message.Message, noCopy bool)
+	rpcCaller  func(ctx context.Context, session *session.Session, route string, data []byte) ([]byte, error)
+	syncer     func(addr string, req *clusterpb.SyncSessionRequest)
+
+	// handshakeCache holds the route dictionary sent in handshake response
+	handshakeCache struct {
+		dict       map[string]uint16
+		dictHash   string              // hash of route dictionary
+		dictionary *message.Dictionary // codec of the routes in dict
+		data       []byte              // handshake response data without negotiation
+	}
+
+	// handshakeRequest represents the system data of client handshake
+	handshakeRequest struct {
+		Sys struct {
+			Protocol     int      `json:"protocol"`     // handshake protocol version
+			Type         string   `json:"type"`         // client type
+			Version      string   `json:"version"`      // client version
+			Capabilities []string `json:"capabilities"` // capabilities supported by client
+			Resume       string   `json:"resume"`       // resume token of the previous session
+			Compress     []string `json:"compress"`     // compression algorithms in preference order
+			CompressDict string   `json:"compressDict"` // checksum of compression dictionary
+			DictHash     string   `json:"dictHash"`     // hash of route dictionary cached by client
+			KeyExchange  string   `json:"keyExchange"`  // key exchange algorithm requested by client
+			PublicKey    string   `json:"publicKey"`    // public key of client in base64
+			Nonce        string   `json:"nonce"`        // random value of client in base64
+		} `json:"sys"`
+	}
+)
 
 func cache() {
-	data, err := json.Marshal(map[string]interface{}{
-		"code": 200,
-		"sys":  map[string]float64{"heartbeat": env.Heartbeat.Seconds()},
-	})
-	if err != nil {
+	var err error
+	if err = cacheHandshake(); err != nil {
 		panic(err)
 	}
 
-	hrd, err = codec.Encode(packet.Handshake, data)
+	hbd, err = codec.Encode(packet.Heartbeat, nil)
 	if err != nil {
 		panic(err)
 	}
+}
 
-	hbd, err = codec.Encode(packet.Heartbeat, nil)
+// cacheHandshake caches the handshake response of current route dictionary
+func cacheHandshake() error {
+	dict := message.Routes()
+	c := &handshakeCache{
+		dict:       dict,
+		dictHash:   message.DictionaryHash(dict),
+		dictionary: message.NewDictionary(dict),
+	}
+	data, err := c.handshakeData(nil, nil, true)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	c.data = data
+	handshakes.Store(c)
+	return nil
+}
+
+func cachedHandshake() *handshakeCache {
+	return handshakes.Load().(*handshakeCache)
+}
+
+// handshakeData returns the handshake response packet, the extra system data
+// negotiated with client will be merged into the sys block, and the user data
+// will be sent in the user block. The route dictionary will be omitted if
+// withDict is false, only the hash of it will be sent
+func (c *handshakeCache) handshakeData(extra map[string]interface{}, user interface{}, withDict bool) ([]byte, error) {
+	sys := map[string]interface{}{"heartbeat": env.Heartbeat.Seconds()}
+	if len(c.dict) > 0 {
+		if withDict {
+			sys["dict"] = c.dict
+		}
+		sys["dictHash"] = c.dictHash
+	}
+	for k, v := range extra {
+		sys[k] = v
+	}
+	res := map[string]interface{}{
+		"code": handshakeOK,
+		"sys":  sys,
+	}
+	if user != nil {
+		res["user"] = user
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(packet.Handshake, data)
+}
+
+// parseHandshake parses the system data of client handshake, the custom data
+// may not be JSON, so the errors are ignored
+func parseHandshake(data []byte) handshakeRequest {
+	req := handshakeRequest{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &req)
+	}
+	return req
 }
 
 type LocalHandler struct {
@@ -83,8 +175,10 @@ type LocalHandler struct {
 	localHandlers map[string]*component.Handler // all handler method
 
 	remoteServices map[string][]*clusterpb.MemberInfo
+	balancers      map[string]balancer.Balancer // balancers of remote services
 
 	pipeline    pipeline.Pipeline
+	limiter     *ratelimit.Limiter
 	currentNode *Node
 }
 
@@ -93,10 +187,17 @@ func NewHandler(currentNode *Node, pipeline pipeline.Pipeline) *LocalHandler {
 		localServices:  make(map[string]*component.Service),
 		localHandlers:  make(map[string]*component.Handler),
 		remoteServices: map[string][]*clusterpb.MemberInfo{},
+		balancers:      map[string]balancer.Balancer{},
 		pipeline:       pipeline,
 		currentNode:    currentNode,
 	}
 
+	config := ratelimit.Config{}
+	if currentNode.RateLimit != nil {
+		config = *currentNode.RateLimit
+	}
+	h.limiter = ratelimit.New(config)
+
 	return h
 }
 
@@ -111,6 +212,12 @@ func (h *LocalHandler) register(comp component.Component, opts []component.Optio
 		return err
 	}
 
+	// the balancer is created by the nodes which call the service, so the
+	// name is validated here rather than failing silently on them
+	if _, err := balancer.New(s.Balancer); err != nil {
+		return fmt.Errorf("handler: service %s: %w", s.Name, err)
+	}
+
 	// register all localHandlers
 	h.localServices[s.Name] = s
 	for name, handler := range s.Handlers {
@@ -128,12 +235,40 @@ func (h *LocalHandler) initRemoteService(members []*clusterpb.MemberInfo) {
 }
 
 func (h *LocalHandler) addRemoteService(member *clusterpb.MemberInfo) {
+	// the clients connected to current node could call the routes of member
+	if err := h.currentNode.extendDictionary(member.Routes); err != nil {
+		log.Println("Extend route dictionary failed", member.ServiceAddr, err)
+	}
+
 	h.Lock()
 	defer h.Unlock()
 
 	for _, s := range member.Services {
 		log.Println("Register remote service", s)
-		h.remoteServices[s] = append(h.remoteServices[s], member)
+		members := h.remoteServices[s]
+		found := false
+		for i, m := range members {
+			if m.ServiceAddr == member.ServiceAddr {
+				// Copy on write, the slice may be iterating by other goroutines
+				members = append([]*clusterpb.MemberInfo{}, members...)
+				members[i] = member
+				found = true
+				break
+			}
+		}
+		if !found {
+			members = append(members[:len(members):len(members)], member)
+		}
+		h.remoteServices[s] = members
+		if _, found := h.balancers[s]; !found {
+			b, err := balancer.New(member.Balancers[s])
+			if err != nil {
+				// the balancer registered by the member only
+				log.Printf("Balancer of service %s unavailable, use %s: %v", s, balancer.Random, err)
+				b = balancer.NewRandom()
+			}
+			h.balancers[s] = b
+		}
 	}
 }
 
@@ -142,19 +277,38 @@ func (h *LocalHandler) delMember(addr string) {
 	defer h.Unlock()
 
 	for name, members := range h.remoteServices {
-		for i, maddr := range members {
-			if addr == maddr.ServiceAddr {
-				members = append(members[:i], members[i+1:]...)
+		// Copy on write, the slice may be iterating by other goroutines
+		remain := make([]*clusterpb.MemberInfo, 0, len(members))
+		for _, m := range members {
+			if addr != m.ServiceAddr {
+				remain = append(remain, m)
 			}
 		}
-		if len(members) == 0 {
+		if len(remain) == 0 {
 			delete(h.remoteServices, name)
+			delete(h.balancers, name)
 		} else {
-			h.remoteServices[name] = members
+			h.remoteServices[name] = remain
 		}
 	}
 }
 
+// LocalBalancers returns the balancer names of local services which specified
+// by component.WithBalancer
+func (h *LocalHandler) LocalBalancers() map[string]string {
+	var result map[string]string
+	for name, service := range h.localServices {
+		if service.Balancer == "" {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[name] = service.Balancer
+	}
+	return result
+}
+
 func (h *LocalHandler) LocalService() []string {
 	var result []string
 	for service := range h.localServices {
@@ -164,6 +318,16 @@ func (h *LocalHandler) LocalService() []string {
 	return result
 }
 
+// LocalRoutes returns the routes of local handlers
+func (h *LocalHandler) LocalRoutes() []string {
+	result := make([]string, 0, len(h.localHandlers))
+	for route := range h.localHandlers {
+		result = append(result, route)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (h *LocalHandler) RemoteService() []string {
 	h.RLock()
 	defer h.RUnlock()
@@ -178,7 +342,10 @@ func (h *LocalHandler) RemoteService() []string {
 
 func (h *LocalHandler) handle(conn net.Conn) {
 	// create a client agent and startup write gorontine
-	agent := newAgent(conn, h.pipeline, h.remoteProcess)
+	agent := newAgent(conn, h.pipeline, h.remoteProcess, h.remoteCall, h.syncState)
+	agent.serializerOf = h.serializerOf
+	agent.decoder.MaxReassemblySize = h.currentNode.maxReassemblySize()
+	agent.decoder.ReassemblyTimeout = h.currentNode.reassemblyTimeout()
 	h.currentNode.storeSession(agent.session)
 
 	// startup write goroutine
@@ -190,30 +357,10 @@ func (h *LocalHandler) handle(conn net.Conn) {
 
 	// guarantee agent related resource be destroyed
 	defer func() {
-		request := &clusterpb.SessionClosedRequest{
-			SessionId: agent.session.ID(),
+		agent.close(true)
+		if !h.currentNode.resumer.park(agent) {
+			h.closeSession(agent)
 		}
-
-		members := h.currentNode.cluster.remoteAddrs()
-		for _, remote := range members {
-			log.Println("Notify remote server success", remote)
-			pool, err := h.currentNode.rpcClient.getConnPool(remote)
-			if err != nil {
-				log.Println("Cannot retrieve connection pool for address", remote, err)
-				continue
-			}
-			client := clusterpb.NewMemberClient(pool.Get())
-			_, err = client.SessionClosed(context.Background(), request)
-			if err != nil {
-				log.Println("Cannot close session in remote address", remote, err)
-				continue
-			}
-			if env.Debug {
-				log.Println("Notify remote server success", remote)
-			}
-		}
-
-		agent.Close()
 		if env.Debug {
 			log.Printf("Session read goroutine exit, SessionID=%d, UID=%d", agent.session.ID(), agent.session.UID())
 		}
@@ -221,7 +368,16 @@ func (h *LocalHandler) handle(conn net.Conn) {
 
 	// read loop
 	buf := make([]byte, 2048)
+	var deadline time.Time
 	for {
+		// the connection times out if the reassembling packet is not completed
+		if d := agent.decoder.Deadline(); !d.Equal(deadline) {
+			if err := conn.SetReadDeadline(d); err != nil {
+				log.Println(err.Error())
+				return
+			}
+			deadline = d
+		}
 		n, err := conn.Read(buf)
 		if err != nil {
 			errMsg := func(str string) string {
@@ -235,7 +391,7 @@ func (h *LocalHandler) handle(conn net.Conn) {
 			return
 		}
 
-		// TODO(warning): decoder use slice for performance, packet data should be copy before next Decode
+		// the packets own their data, so buf could be reused by the next read
 		packets, err := agent.decoder.Decode(buf[:n])
 		if err != nil {
 			log.Println(err.Error())
@@ -260,24 +416,57 @@ func (h *LocalHandler) handle(conn net.Conn) {
 	}
 }
 
+// closeSession notifies the remote members that session has been closed, the
+// session lifetime callbacks will be fired if the agent closed by broken connection
+func (h *LocalHandler) closeSession(agent *agent) {
+	request := &clusterpb.SessionClosedRequest{
+		SessionId: agent.session.ID(),
+	}
+
+	members := h.currentNode.cluster.remoteAddrs()
+	for _, remote := range members {
+		log.Println("Notify remote server success", remote)
+		pool, err := h.currentNode.rpcClient.getConnPool(remote)
+		if err != nil {
+			log.Println("Cannot retrieve connection pool for address", remote, err)
+			continue
+		}
+		client := clusterpb.NewMemberClient(pool.Get())
+		_, err = client.SessionClosed(context.Background(), request)
+		if err != nil {
+			log.Println("Cannot close session in remote address", remote, err)
+			continue
+		}
+		if env.Debug {
+			log.Println("Notify remote server success", remote)
+		}
+	}
+
+	h.unbindSession(agent.session, "")
+	h.limiter.Forget(agent.session.ID(), agent.session.UID())
+	if agent.isDetached() {
+		scheduler.PushTask(func() { session.Lifetime.Close(agent.session) })
+	}
+}
+
 func (h *LocalHandler) processPacket(agent *agent, p *packet.Packet) error {
 	switch p.Type {
 	case packet.Handshake:
-		if err := env.HandshakeValidator(p.Data); err != nil {
-			return err
+		// the handshake could resume other session, it is only allowed once
+		// before the connection starts working
+		if agent.status() != statusStart {
+			return fmt.Errorf("receive handshake on socket which already handshaked, session will be closed immediately, remote=%s",
+				agent.conn.RemoteAddr().String())
 		}
-
-		if _, err := agent.conn.Write(hrd); err != nil {
+		if err := h.handshake(agent, p); err != nil {
 			return err
 		}
 
-		agent.setStatus(statusHandshake)
-		if env.Debug {
-			log.Printf("Session handshake Id=%d, Remote=%s", agent.session.ID(), agent.conn.RemoteAddr())
-		}
-
 	case packet.HandshakeAck:
 		agent.setStatus(statusWorking)
+		if err := agent.resume(); err != nil {
+			return err
+		}
 		if env.Debug {
 			log.Printf("Receive handshake ACK Id=%d, Remote=%s", agent.session.ID(), agent.conn.RemoteAddr())
 		}
@@ -288,7 +477,10 @@ func (h *LocalHandler) processPacket(agent *agent, p *packet.Packet) error {
 				agent.conn.RemoteAddr().String())
 		}
 
-		msg, err := message.Decode(p.Data)
+		if err := agent.getCompression().Decompress(p); err != nil {
+			return err
+		}
+		msg, err := agent.getDictionary().Decode(p.Data)
 		if err != nil {
 			return err
 		}
@@ -302,39 +494,195 @@ func (h *LocalHandler) processPacket(agent *agent, p *packet.Packet) error {
 	return nil
 }
 
+// handshake negotiates with the client and sends the handshake response. The
+// detached session of the resume token is adopted only after the response has
+// been sent, it is put back to resumer if the handshake failed, so the client
+// could still resume it with the same token
+func (h *LocalHandler) handshake(agent *agent, p *packet.Packet) (err error) {
+	if err := env.HandshakeValidator(p.Data); err != nil {
+		return err
+	}
+
+	req := parseHandshake(p.Data)
+	info := h.currentNode.handshakeInfo(req)
+	if h.currentNode.outdated(info) {
+		return refuseHandshake(agent, handshakeOutdated, fmt.Errorf("%w: type=%s, version=%s, remote=%s", ErrClientOutdated, info.Type, info.Version, agent.conn.RemoteAddr()))
+	}
+
+	sys := map[string]interface{}{}
+	if info.Protocol > 0 {
+		sys["protocol"] = ProtocolVersion
+		sys["capabilities"] = info.Capabilities
+	}
+	compression, err := h.currentNode.negotiateCompression(req, sys)
+	if err != nil {
+		return err
+	}
+	cipher, err := h.currentNode.exchangeKey(req, sys)
+	if err != nil {
+		return err
+	}
+	// the connection stages declare the negotiations they depend on, e.g. the
+	// payloads could not be decrypted without the session cipher
+	var requires pipeline.Requirement
+	if h.pipeline != nil {
+		requires = h.pipeline.Inbound().Requires()
+	}
+	if cipher == nil && requires&pipeline.RequireCipher != 0 {
+		return refuseHandshake(agent, handshakeRejected, fmt.Errorf("%w: remote=%s", ErrKeyRequired, agent.conn.RemoteAddr()))
+	}
+	nonce, err := exchangeNonce(req, sys)
+	if err != nil {
+		return err
+	}
+	// the sequenced messages are bound to the nonce of connection, they
+	// could be replayed on other connections without nonce
+	if nonce == nil && requires&pipeline.RequireNonce != 0 {
+		return refuseHandshake(agent, handshakeRejected, fmt.Errorf("%w: remote=%s", ErrNonceRequired, agent.conn.RemoteAddr()))
+	}
+
+	r := h.currentNode.resumer
+	s := agent.session
+	parked := r.resume(req.Sys.Resume)
+	if parked != nil {
+		s = parked.session
+		defer func() {
+			if err != nil {
+				r.restore(parked)
+			}
+		}()
+	}
+	s.SetHandshakeInfo(info)
+	s.SetCipher(cipher)
+	s.SetNonce(nonce)
+
+	var user interface{}
+	if fn := h.currentNode.HandshakeHandler; fn != nil {
+		if user, err = fn(s); err != nil {
+			return refuseHandshake(agent, handshakeRejected, err)
+		}
+	}
+	if r.enabled() {
+		agent.token = r.token()
+		sys["resume"] = agent.token
+		sys["resumed"] = parked != nil
+	}
+
+	// the client cached the same dictionary does not need to download it again
+	cached := cachedHandshake()
+	withDict := req.Sys.DictHash == "" || req.Sys.DictHash != cached.dictHash
+	data := cached.data
+	if len(sys) > 0 || user != nil || !withDict {
+		if data, err = cached.handshakeData(sys, user, withDict); err != nil {
+			return err
+		}
+	}
+	agent.setDictionary(cached.dictionary)
+	if _, err := agent.conn.Write(data); err != nil {
+		return err
+	}
+	if parked != nil {
+		h.currentNode.removeSession(agent.session.ID())
+		agent.adopt(parked)
+	}
+	agent.setCompression(compression)
+
+	agent.setStatus(statusHandshake)
+	if env.Debug {
+		log.Printf("Session handshake Id=%d, Remote=%s", agent.session.ID(), agent.conn.RemoteAddr())
+	}
+	return nil
+}
+
 func (h *LocalHandler) findMembers(service string) []*clusterpb.MemberInfo {
 	h.RLock()
 	defer h.RUnlock()
 	return h.remoteServices[service]
 }
 
-func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Message, noCopy bool) {
-	index := strings.LastIndex(msg.Route, ".")
+// selectRemote returns the remote address which provides the service of route
+// 1. Use the service address directly if the router contains binding item
+// 2. Select a remote service address by the balancer of service and bind to router
+func (h *LocalHandler) selectRemote(session *session.Session, route string) (string, error) {
+	index := strings.LastIndex(route, ".")
 	if index < 0 {
-		log.Printf("amoeba/handler: invalid route %s", msg.Route)
-		return
+		return "", ErrInvalidRoute
 	}
 
-	service := msg.Route[:index]
+	service := route[:index]
 	members := h.findMembers(service)
 	if len(members) == 0 {
-		log.Printf("amoeba/handler: msg route %s not found", msg.Route)
-		return
+		return "", ErrRouteNotFound
+	}
+	b := h.findBalancer(service)
+
+	// The node itself does not hold a router, so it is not necessary to bind
+	if session == nil {
+		return b.Select(nil, members).ServiceAddr, nil
 	}
 
-	// Select a remote service address
-	// 1. Use the service address directly if the router contains binding item
-	// 2. Select a remote service address randomly and bind to router
-	var remoteAddr string
 	if addr, found := session.Router().Find(service); found {
-		remoteAddr = addr
-	} else {
-		remoteAddr = members[rand.Intn(len(members))].ServiceAddr
-		session.Router().Bind(service, remoteAddr)
+		return addr, nil
 	}
-	pool, err := h.currentNode.rpcClient.getConnPool(remoteAddr)
+	addr := b.Select(session, members).ServiceAddr
+	session.Router().Bind(service, addr)
+	if t, ok := b.(balancer.Tracker); ok {
+		t.Bind(session, addr)
+	}
+	return addr, nil
+}
+
+func (h *LocalHandler) findBalancer(service string) balancer.Balancer {
+	h.RLock()
+	b, found := h.balancers[service]
+	h.RUnlock()
+	if !found {
+		return balancer.NewRandom()
+	}
+	return b
+}
+
+// unbindSession notifies the balancers that session is not bound to the
+// member of service any more, all services will be notified if service is empty
+func (h *LocalHandler) unbindSession(session *session.Session, service string) {
+	h.RLock()
+	defer h.RUnlock()
+	for name, b := range h.balancers {
+		if service != "" && service != name {
+			continue
+		}
+		if t, ok := b.(balancer.Tracker); ok {
+			t.Unbind(session)
+		}
+	}
+}
+
+// sessionOrigin returns the gate address and the gate session id of session
+func (h *LocalHandler) sessionOrigin(session *session.Session) (string, int64) {
+	if session == nil {
+		return "", 0
+	}
+	gateAddr := h.currentNode.ServiceAddr
+	sessionId := session.ID()
+	switch v := session.NetworkEntity().(type) {
+	case *acceptor:
+		gateAddr = v.gateAddr
+		sessionId = v.sid
+	}
+	return gateAddr, sessionId
+}
+
+func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Message, noCopy bool) {
+	h.forwardProcess(session, msg, noCopy, nil)
+}
+
+// forwardProcess sends the message to the remote service, processed is the
+// names of the inbound stages which the message has passed through
+func (h *LocalHandler) forwardProcess(session *session.Session, msg *message.Message, noCopy bool, processed []string) {
+	remoteAddr, err := h.selectRemote(session, msg.Route)
 	if err != nil {
-		log.Println(err)
+		log.Printf("amoeba/handler: msg route %s error: %v", msg.Route, err)
+		abortRequest(session, msg.ID, err, errors.CodeNotFound)
 		return
 	}
 	var data = msg.Data
@@ -344,15 +692,16 @@ func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Mess
 	}
 
 	// Retrieve gate address and session id
-	gateAddr := h.currentNode.ServiceAddr
-	sessionId := session.ID()
-	switch v := session.NetworkEntity().(type) {
-	case *acceptor:
-		gateAddr = v.gateAddr
-		sessionId = v.sid
+	gateAddr, sessionId := h.sessionOrigin(session)
+	md := map[string]string{component.TraceIDKey: newTraceID()}
+	if h.currentNode.StreamTransport {
+		setStreamTimeout(md, env.CallTimeout)
+	}
+	if len(processed) > 0 {
+		md[inboundProcessedKey] = strings.Join(processed, ",")
 	}
 
-	client := clusterpb.NewMemberClient(pool.Get())
+	var stream *clusterpb.StreamMessage
 	switch msg.Type {
 	case message.Request:
 		request := &clusterpb.RequestMessage{
@@ -361,20 +710,107 @@ func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Mess
 			Id:        msg.ID,
 			Route:     msg.Route,
 			Data:      data,
+			Metadata:  md,
 		}
-		_, err = client.HandleRequest(context.Background(), request)
+		stream = &clusterpb.StreamMessage{Message: &clusterpb.StreamMessage_Request{Request: request}}
 	case message.Notify:
 		request := &clusterpb.NotifyMessage{
 			GateAddr:  gateAddr,
 			SessionId: sessionId,
 			Route:     msg.Route,
 			Data:      data,
+			Metadata:  md,
 		}
-		_, err = client.HandleNotify(context.Background(), request)
+		stream = &clusterpb.StreamMessage{Message: &clusterpb.StreamMessage_Notify{Notify: request}}
+	default:
+		return
+	}
+
+	if h.currentNode.StreamTransport {
+		err = h.currentNode.streamer.send(remoteAddr, stream)
+	} else {
+		err = h.forward(remoteAddr, stream)
 	}
 	if err != nil {
 		log.Printf("Process remote message (%d:%s) error: %+v", msg.ID, msg.Route, err)
+		abortRequest(session, msg.ID, err, errors.CodeUnavailable)
+	}
+}
+
+// forward sends the message to remote member with unary call, the deadline of
+// call will be taken by the context-aware handler of remote member
+func (h *LocalHandler) forward(remoteAddr string, msg *clusterpb.StreamMessage) error {
+	pool, err := h.currentNode.rpcClient.getConnPool(remoteAddr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	client := clusterpb.NewMemberClient(pool.Get())
+	switch m := msg.Message.(type) {
+	case *clusterpb.StreamMessage_Request:
+		_, err = client.HandleRequest(ctx, m.Request)
+	case *clusterpb.StreamMessage_Notify:
+		_, err = client.HandleNotify(ctx, m.Notify)
+	}
+	return err
+}
+
+// remoteCall sends a request to the remote service and blocks until the remote
+// handler responds or the deadline exceeded. The session may be nil when the call
+// is started by node itself
+func (h *LocalHandler) remoteCall(ctx context.Context, session *session.Session, route string, data []byte) ([]byte, error) {
+	remoteAddr, err := h.selectRemote(session, route)
+	if err != nil {
+		return nil, err
 	}
+	pool, err := h.currentNode.rpcClient.getConnPool(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, env.CallTimeout)
+		defer cancel()
+	}
+
+	// propagate the trace metadata of the request which started the call
+	md := map[string]string{component.TraceIDKey: newTraceID()}
+	if r, ok := component.FromContext(ctx); ok && r.Metadata[component.TraceIDKey] != "" {
+		md = r.Metadata
+	}
+
+	gateAddr, sessionId := h.sessionOrigin(session)
+	request := &clusterpb.CallRequest{
+		GateAddr:  gateAddr,
+		SessionId: sessionId,
+		Route:     route,
+		Data:      data,
+		Metadata:  md,
+	}
+	client := clusterpb.NewMemberClient(pool.Get())
+	resp, err := client.HandleCall(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error {
+		return nil, errors.Decode(resp.Data)
+	}
+	return resp.Data, nil
+}
+
+// callReply returns the response of remote call, the error is sent as the
+// envelope, so the caller rebuilds the *errors.Error with its code and details
+func callReply(r callResult) (*clusterpb.CallResponse, error) {
+	if r.err == nil {
+		return &clusterpb.CallResponse{Data: r.data}, nil
+	}
+	data, err := errors.Encode(errors.From(r.err, errors.CodeInternal))
+	if err != nil {
+		return nil, err
+	}
+	return &clusterpb.CallResponse{Data: data, Error: true}, nil
 }
 
 func (h *LocalHandler) processMessage(agent *agent, msg *message.Message) {
@@ -389,11 +825,30 @@ func (h *LocalHandler) processMessage(agent *agent, msg *message.Message) {
 		return
 	}
 
+	if !h.limiter.Allow(agent.session.ID(), agent.session.UID(), msg.Route) {
+		h.rateLimited(agent.session, lastMid, msg.Route)
+		return
+	}
+
 	handler, found := h.localHandlers[msg.Route]
 	if !found {
-		h.remoteProcess(agent.session, msg, false)
+		// the messages of remote services pass through the connection stages
+		// of gate only, e.g. decrypted by the session cipher, the remaining
+		// inbound stages run on the remote member
+		var processed []string
+		if pipe := h.pipeline; pipe != nil {
+			var err error
+			processed, err = pipe.Inbound().ProcessNamed(agent.session, msg, pipe.Inbound().ConnectionStages()...)
+			if err != nil {
+				log.Println("Pipeline process failed: " + err.Error())
+				abortRequest(agent.session, lastMid, err, errors.CodeRejected)
+				return
+			}
+		}
+		// the packet data is owned by the message, see codec.Decoder
+		h.forwardProcess(agent.session, msg, true, processed)
 	} else {
-		h.localProcess(handler, lastMid, agent.session, msg)
+		h.localProcess(context.Background(), handler, lastMid, agent.session, msg, nil)
 	}
 }
 
@@ -406,11 +861,25 @@ func (h *LocalHandler) handleWS(conn *websocket.Conn) {
 	go h.handle(c)
 }
 
-func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64, session *session.Session, msg *message.Message) {
+// localProcess invokes the local handler with the message, the deadline of ctx
+// and the metadata md will be taken by the context-aware handler
+func (h *LocalHandler) localProcess(ctx context.Context, handler *component.Handler, lastMid uint64, session *session.Session, msg *message.Message, md map[string]string) {
+	if !h.limiter.AllowRoute(session.ID(), msg.Route, handler.RateLimit) {
+		h.rateLimited(session, lastMid, msg.Route)
+		return
+	}
+
+	// the stages processed by gate are not processed again
+	var processed []string
+	if v, ok := md[inboundProcessedKey]; ok {
+		processed = strings.Split(v, ",")
+		delete(md, inboundProcessedKey)
+	}
 	if pipe := h.pipeline; pipe != nil {
-		err := pipe.Inbound().Process(session, msg)
+		err := pipe.Inbound().ProcessExcept(session, msg, processed...)
 		if err != nil {
 			log.Println("Pipeline process failed: " + err.Error())
+			abortRequest(session, lastMid, err, errors.CodeRejected)
 			return
 		}
 	}
@@ -422,9 +891,14 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 	} else {
 		data = reflect.New(handler.Type.Elem()).Interface()
 		// log.Println(data)
-		err := env.Serializer.Unmarshal(payload, data)
+		serializer := handler.Serializer
+		if serializer == nil {
+			serializer = env.Serializer
+		}
+		err := serializer.Unmarshal(payload, data)
 		if err != nil {
 			log.Printf("Deserialize to %T failed: %+v (%v)", data, err, payload)
+			abortRequest(session, lastMid, err, errors.CodeBadRequest)
 			return
 		}
 	}
@@ -433,8 +907,37 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		log.Printf("UID=%d, Message={%s}, Data=%+v", session.UID(), msg.String(), data)
 	}
 
-	args := []reflect.Value{handler.Receiver, reflect.ValueOf(session), reflect.ValueOf(data)}
+	// the response will be serialized by the serializer of handler
+	withSerializer := handler.Serializer != nil && lastMid > 0
+	if withSerializer {
+		if p := serializersOf(session); p != nil {
+			p.set(lastMid, handler.Serializer)
+		}
+	}
+
+	// the context is required by the context-aware handler and interceptors,
+	// and the deadline of request is counted since it arrived
+	withCtx := handler.IsCtxArg || len(handler.Interceptors) > 0
+	var deadline time.Time
+	if withCtx {
+		deadline, _ = ctx.Deadline()
+		if t := handler.Timeout; t > 0 {
+			if d := time.Now().Add(t); deadline.IsZero() || d.Before(deadline) {
+				deadline = d
+			}
+		}
+	}
+
 	task := func() {
+		// the serializer is forgotten if the handler returned without
+		// responding, the entity may be changed by resuming during handling
+		if withSerializer {
+			defer func() {
+				if p := serializersOf(session); p != nil {
+					p.take(lastMid)
+				}
+			}()
+		}
 		switch v := session.NetworkEntity().(type) {
 		case *agent:
 			v.lastMid = lastMid
@@ -442,10 +945,69 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 			v.lastMid = lastMid
 		}
 
-		result := handler.Method.Func.Call(args)
-		if len(result) > 0 {
-			if err := result[0].Interface(); err != nil {
-				log.Printf("Service %s error: %+v", msg.Route, err)
+		args := []reflect.Value{handler.Receiver, reflect.ValueOf(session), reflect.ValueOf(data)}
+		var ctx context.Context
+		if withCtx {
+			var cancel context.CancelFunc
+			ctx, cancel = handlerContext(session, lastMid, msg.Route, md, deadline)
+			defer cancel()
+			if err := ctx.Err(); err != nil {
+				log.Printf("Service %s abandoned: %v", msg.Route, err)
+				abortRequest(session, lastMid, err, errors.CodeTimeout)
+				return
+			}
+			if handler.IsCtxArg {
+				args = []reflect.Value{handler.Receiver, reflect.ValueOf(ctx), reflect.ValueOf(session), reflect.ValueOf(data)}
+			}
+		}
+
+		invoke := func(inv *component.Invocation) (interface{}, error) {
+			// the argument may be replaced by interceptors
+			if inv != nil {
+				if inv.Arg == nil {
+					args[len(args)-1] = reflect.Zero(handler.Type)
+				} else if arg := reflect.ValueOf(inv.Arg); arg.Type().AssignableTo(handler.Type) {
+					args[len(args)-1] = arg
+				} else {
+					return nil, fmt.Errorf("argument %T is not assignable to %s", inv.Arg, handler.Type)
+				}
+			}
+			result := handler.Method.Func.Call(args)
+			var err error
+			if e := result[len(result)-1].Interface(); e != nil {
+				err = e.(error)
+			}
+			if handler.IsReply {
+				return result[0].Interface(), err
+			}
+			return nil, err
+		}
+
+		var reply interface{}
+		var err error
+		if len(handler.Interceptors) > 0 {
+			reply, err = handler.Intercept(&component.Invocation{
+				Context: ctx,
+				Session: session,
+				ID:      lastMid,
+				Route:   msg.Route,
+				Handler: handler,
+				Arg:     data,
+			}, invoke)
+		} else {
+			reply, err = invoke(nil)
+		}
+		if err != nil {
+			log.Printf("Service %s error: %+v", msg.Route, err)
+			abortRequest(session, lastMid, err, errors.CodeInternal)
+			return
+		}
+
+		// the returned response is bound to the message id, so it will not be
+		// sent to other request even if lastMid has been changed
+		if (handler.IsReply || reply != nil) && lastMid > 0 {
+			if err := session.ResponseMID(lastMid, reply); err != nil {
+				log.Printf("Service %s response error: %+v", msg.Route, err)
 			}
 		}
 	}
@@ -453,6 +1015,7 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 	index := strings.LastIndex(msg.Route, ".")
 	if index < 0 {
 		log.Printf("amoeba/handler: invalid route %s", msg.Route)
+		abortRequest(session, lastMid, ErrInvalidRoute, errors.CodeNotFound)
 		return
 	}
 
@@ -462,6 +1025,7 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		sched := session.Value(s.SchedName)
 		if sched == nil {
 			log.Printf("nanl/handler: cannot found `schedular.LocalScheduler` by %s", s.SchedName)
+			abortRequest(session, lastMid, ErrRouteNotFound, errors.CodeNotFound)
 			return
 		}
 
@@ -469,6 +1033,7 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		if !ok {
 			log.Printf("nanl/handler: Type %T does not implement the `schedular.LocalScheduler` interface",
 				sched)
+			abortRequest(session, lastMid, ErrRouteNotFound, errors.CodeNotFound)
 			return
 		}
 		local.Schedule(task)
@@ -476,3 +1041,69 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		scheduler.PushTask(task)
 	}
 }
+
+// rateLimited treats the message exceeded rate limit according to the policy
+func (h *LocalHandler) rateLimited(s *session.Session, mid uint64, route string) {
+	if env.Debug {
+		log.Printf("Rate limit exceeded, ID=%d, UID=%d, Route=%s", s.ID(), s.UID(), route)
+	}
+	switch h.limiter.Policy() {
+	case ratelimit.Reject:
+		abortRequest(s, mid, ErrRateLimited, errors.CodeRateLimited)
+	case ratelimit.Kick:
+		if err := s.Kick(ErrRateLimited.Error()); err != nil {
+			log.Println("Kick session exceeded rate limit failed", err)
+		}
+	}
+}
+
+// errorResponder is implemented by the network entities which are able to send
+// the error response of request
+type errorResponder interface {
+	responseError(mid uint64, data []byte) error
+}
+
+// abortRequest terminates the request with err, the error envelope will be sent
+// as the response, or delivered to the pending remote call. The err which is
+// not an *errors.Error will be wrapped with code, but only the generic message
+// of code is sent to client, its text stays in the log of server. Nothing will
+// be sent for the notify, whose mid is zero
+func abortRequest(s *session.Session, mid uint64, err error, code int) {
+	if mid == 0 {
+		return
+	}
+	if a, ok := s.NetworkEntity().(*acceptor); ok && mid&callMidMask != 0 {
+		a.finishCall(mid, callResult{err: errors.From(err, code)})
+		return
+	}
+	r, ok := s.NetworkEntity().(errorResponder)
+	if !ok {
+		return
+	}
+	data, err := errors.Encode(errors.Public(err, code))
+	if err != nil {
+		log.Printf("Encode error response(id: %d) failed: %v", mid, err)
+		return
+	}
+	if err := r.responseError(mid, data); err != nil {
+		log.Printf("Response error(id: %d) failed: %v", mid, err)
+	}
+}
+
+// handlerContext returns the context passed to the context-aware handler, it is
+// derived from the context of session, so it will be cancelled once the session
+// closed. The trace id will be assigned if it is not propagated from other member
+func handlerContext(s *session.Session, mid uint64, route string, md map[string]string, deadline time.Time) (context.Context, context.CancelFunc) {
+	if md[component.TraceIDKey] == "" {
+		md = map[string]string{component.TraceIDKey: newTraceID()}
+	}
+	ctx := component.NewContext(s.Context(), &component.Request{ID: mid, Route: route, Metadata: md})
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+func newTraceID() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,163 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/session"
+)
+
+// ProtocolVersion is the handshake protocol version of current node, which
+// will be sent to clients in handshake response
+const ProtocolVersion = 1
+
+// HandshakeHandler is called after the client handshake parsed, the returned
+// data will be sent to client in the user block of handshake response, and the
+// handshake will be rejected with code 403 if an error returned
+type HandshakeHandler func(s *session.Session) (interface{}, error)
+
+// Handshake response codes
+const (
+	handshakeOK       = 200
+	handshakeRejected = 403 // rejected by the handshake handler or connection stages
+	handshakeOutdated = 501 // the client version is lower than the required
+)
+
+// handshakeInfo returns the handshake info of client, the capabilities are
+// the ones supported by both client and current node
+func (n *Node) handshakeInfo(req handshakeRequest) *session.HandshakeInfo {
+	info := &session.HandshakeInfo{
+		Protocol: req.Sys.Protocol,
+		Type:     req.Sys.Type,
+		Version:  req.Sys.Version,
+	}
+	for _, c := range req.Sys.Capabilities {
+		for _, enabled := range n.Capabilities {
+			if c == enabled {
+				info.Capabilities = append(info.Capabilities, c)
+				break
+			}
+		}
+	}
+	return info
+}
+
+// outdated reports whether the client version is lower than the minimum
+// version of the client type, the client without type is treated as outdated
+// if the minimum versions are required
+func (n *Node) outdated(info *session.HandshakeInfo) bool {
+	if len(n.ClientVersions) == 0 {
+		return false
+	}
+	if info.Type == "" {
+		return true
+	}
+	min, found := n.ClientVersions[info.Type]
+	if !found {
+		return false
+	}
+	return compareVersion(info.Version, min) < 0
+}
+
+// compareVersion compares the dot separated versions, the numeric parts are
+// compared numerically and others lexically, the missing parts are treated as
+// zero, e.g. 1.2 equals to 1.2.0 and 1.10.0 is greater than 1.9.1
+func compareVersion(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// nonceSize is the size of random value generated by each side in handshake
+const nonceSize = 16
+
+// exchangeNonce returns the nonce of connection, which consists of the random
+// values of client and current node, so neither side could reuse the nonce of
+// previous connection. The random value of current node will be set into sys
+func exchangeNonce(req handshakeRequest, sys map[string]interface{}) ([]byte, error) {
+	if req.Sys.Nonce == "" {
+		return nil, nil
+	}
+	clientNonce, err := base64.StdEncoding.DecodeString(req.Sys.Nonce)
+	if err != nil || len(clientNonce) != nonceSize {
+		return nil, ErrInvalidNonce
+	}
+	serverNonce := make([]byte, nonceSize)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, err
+	}
+	sys["nonce"] = base64.StdEncoding.EncodeToString(serverNonce)
+	return append(clientNonce, serverNonce...), nil
+}
+
+// refuseHandshake sends the handshake response with code to client, err is
+// returned to close the connection once the response sent
+func refuseHandshake(a *agent, code int, err error) error {
+	data, rerr := handshakeRefused(code)
+	if rerr != nil {
+		return rerr
+	}
+	if _, rerr := a.conn.Write(data); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// handshakeRefused returns the handshake response packet with code
+func handshakeRefused(code int) ([]byte, error) {
+	data, err := json.Marshal(map[string]interface{}{"code": code})
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(packet.Handshake, data)
+}
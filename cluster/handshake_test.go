@@ -0,0 +1,79 @@
+package cluster_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+)
+
+func (s *nodeSuite) TestCompareVersion(c *C) {
+	cases := []struct {
+		a, b   string
+		result int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.1", 1},
+		{"0.9", "1.0", -1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+	}
+	for _, cs := range cases {
+		c.Assert(cluster.CompareVersion(cs.a, cs.b), Equals, cs.result, Commentf("%s %s", cs.a, cs.b))
+	}
+}
+
+func (s *nodeSuite) TestHandshakeInfo(c *C) {
+	chInfo := make(chan *session.HandshakeInfo, 1)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:       true,
+			Components:     &component.Components{},
+			ClientAddr:     "127.0.0.1:24551",
+			ClientVersions: map[string]string{"bot": "1.10"},
+			Capabilities:   []string{"chat", "voice"},
+			HandshakeHandler: func(s *session.Session) (interface{}, error) {
+				info := s.HandshakeInfo()
+				if info.Version == "1.99" {
+					return nil, errors.New("banned version")
+				}
+				chInfo <- info
+				return map[string]string{"motd": "welcome " + info.Version}, nil
+			},
+		},
+		ServiceAddr: "127.0.0.1:24550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	cl := dial(c, "127.0.0.1:24551",
+		client.WithClientVersion("bot", "1.10.1"),
+		client.WithCapabilities("voice", "video"))
+	defer cl.Close()
+	c.Assert(cl.Capabilities(), DeepEquals, []string{"voice"})
+
+	info := <-chInfo
+	c.Assert(info.Protocol, Equals, cluster.ProtocolVersion)
+	c.Assert(info.Type, Equals, "bot")
+	c.Assert(info.Has("voice"), IsTrue)
+	c.Assert(info.Has("chat"), IsFalse)
+
+	_, err := client.Dial("127.0.0.1:24551", client.WithClientVersion("bot", "1.9.2"))
+	c.Assert(err, Equals, client.ErrOutdated)
+
+	// the client without type does not skip the version requirements
+	_, err = client.Dial("127.0.0.1:24551", client.WithClientVersion("", "2.0"))
+	c.Assert(err, Equals, client.ErrOutdated)
+
+	// the error of handshake handler is responded as well
+	_, err = client.Dial("127.0.0.1:24551", client.WithClientVersion("bot", "1.99"))
+	c.Assert(err, Equals, client.ErrRejected)
+
+	user := map[string]string{}
+	c.Assert(json.Unmarshal(cl.HandshakeData(), &user), IsNil)
+	c.Assert(user["motd"], Equals, "welcome 1.10.1")
+}
@@ -0,0 +1,218 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Every member sends heartbeat to the leader of master nodes periodically, and
+// the leader evicts the members which have missed too many heartbeats, so that
+// the crashed members will not stay in the cluster forever.
+
+const (
+	defaultHeartbeatInterval   = 3 * time.Second
+	defaultMaxMissedHeartbeats = 3
+)
+
+func (c *cluster) heartbeatInterval() time.Duration {
+	if d := c.currentNode.MemberHeartbeat; d > 0 {
+		return d
+	}
+	return defaultHeartbeatInterval
+}
+
+func (c *cluster) maxMissedHeartbeats() int {
+	if n := c.currentNode.MaxMissedHeartbeats; n > 0 {
+		return n
+	}
+	return defaultMaxMissedHeartbeats
+}
+
+// Heartbeat implements the MasterServer gRPC service
+func (c *cluster) Heartbeat(ctx context.Context, req *clusterpb.HeartbeatRequest) (*clusterpb.HeartbeatResponse, error) {
+	if req.ServiceAddr == "" {
+		return nil, ErrInvalidRegisterReq
+	}
+
+	// Followers forward the request to leader transparently
+	if !c.isLeader() {
+		client, err := c.leaderClient()
+		if err != nil {
+			return nil, err
+		}
+		return client.Heartbeat(ctx, req)
+	}
+
+	found := false
+	c.Lock()
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == req.ServiceAddr {
+			m.lastHeartbeat = time.Now()
+			found = true
+			break
+		}
+	}
+	c.Unlock()
+
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "address %s has not registered", req.ServiceAddr)
+	}
+	return &clusterpb.HeartbeatResponse{}, nil
+}
+
+// evictMembers removes the members which have missed too many heartbeats, it
+// does nothing if current node is not the leader
+func (c *cluster) evictMembers() {
+	if !c.isLeader() {
+		return
+	}
+
+	now := time.Now()
+	deadline := c.heartbeatInterval() * time.Duration(c.maxMissedHeartbeats())
+	var dead []string
+	c.Lock()
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == c.currentNode.ServiceAddr {
+			continue
+		}
+		// The members replicated from previous leader have no heartbeat
+		// record, give them a full period to report to the new leader
+		if m.lastHeartbeat.IsZero() {
+			m.lastHeartbeat = now
+			continue
+		}
+		if now.Sub(m.lastHeartbeat) > deadline {
+			dead = append(dead, m.memberInfo.ServiceAddr)
+		}
+	}
+	c.Unlock()
+
+	for _, addr := range dead {
+		log.Println("Evict member which missed heartbeats", addr)
+		c.removeMember(addr)
+	}
+}
+
+// keepalive sends heartbeat to the master nodes periodically until the node
+// shutdown, the leader also checks the heartbeats of other members
+func (n *Node) keepalive() {
+	ticker := time.NewTicker(n.cluster.heartbeatInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n.IsMaster {
+				n.cluster.evictMembers()
+			}
+			n.heartbeat()
+
+		case <-n.chDie:
+			return
+		}
+	}
+}
+
+func (n *Node) heartbeat() {
+	request := &clusterpb.HeartbeatRequest{ServiceAddr: n.ServiceAddr}
+	ctx, cancel := context.WithTimeout(context.Background(), n.cluster.heartbeatInterval())
+	defer cancel()
+
+	// Master nodes report to the leader directly
+	if n.IsMaster {
+		if _, err := n.cluster.Heartbeat(ctx, request); err != nil && status.Code(err) != codes.NotFound {
+			log.Println("Send heartbeat to leader failed", err)
+		}
+		return
+	}
+
+	var err error
+	masters := n.cluster.masterAddrs()
+	for _, addr := range masters {
+		var pool *connPool
+		pool, err = n.rpcClient.getConnPool(addr)
+		if err != nil {
+			continue
+		}
+		_, err = clusterpb.NewMasterClient(pool.Get()).Heartbeat(ctx, request)
+		if err == nil || status.Code(err) == codes.NotFound {
+			break
+		}
+	}
+
+	// Current node has been evicted, e.g: network partition, register it again
+	if status.Code(err) == codes.NotFound {
+		log.Println("Current node has been evicted from cluster, register again")
+		n.reregister(masters)
+		return
+	}
+	if err != nil {
+		log.Println("Send heartbeat to master failed", err)
+	}
+}
+
+func (n *Node) reregister(masters []string) {
+	request := &clusterpb.RegisterRequest{
+		MemberInfo: n.memberInfo(),
+	}
+	for _, addr := range masters {
+		resp, err := n.register(addr, request)
+		if err != nil {
+			continue
+		}
+		n.handler.initRemoteService(resp.Members)
+		n.cluster.initMembers(resp.Members)
+		return
+	}
+}
+
+// delMember removes the services provided by the member, and unbinds the
+// sessions routed to it, so that the sessions will be re-routed. The callbacks
+// registered by OnMemberRemoved are called at last
+func (n *Node) delMember(addr string) {
+	n.handler.delMember(addr)
+	n.cluster.delMember(addr)
+
+	n.RLock()
+	for _, s := range n.sessions {
+		router := s.Router()
+		router.Range(func(service, address string) bool {
+			if address == addr {
+				router.Unbind(service)
+				n.handler.unbindSession(s, service)
+			}
+			return true
+		})
+	}
+	callbacks := n.onMemberRemoved
+	n.RUnlock()
+
+	for _, h := range callbacks {
+		h(addr)
+	}
+}
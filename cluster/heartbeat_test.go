@@ -0,0 +1,104 @@
+package cluster_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/io"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+)
+
+type BackupComponent struct{ component.Base }
+
+func (c *BackupComponent) Test(session *session.Session, _ []byte) error {
+	return session.Push("test", &testdata.Pong{Content: "backup server pong"})
+}
+
+func waitNoRemoteService(c *C, node *cluster.Node, service string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		found := false
+		for _, s := range node.Handler().RemoteService() {
+			if s == service {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Fatalf("service %s not evicted", service)
+}
+
+func (s *nodeSuite) TestMemberEviction(c *C) {
+	options := func(comps *component.Components) cluster.Options {
+		return cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:15550",
+			MemberHeartbeat: 50 * time.Millisecond,
+			Components:      comps,
+		}
+	}
+
+	masterComps := &component.Components{}
+	masterComps.Register(&MasterComponent{})
+	master := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			MemberHeartbeat: 50 * time.Millisecond,
+			Components:      masterComps,
+		},
+		ServiceAddr: "127.0.0.1:15550",
+	}
+	c.Assert(master.Startup(), IsNil)
+
+	gateComps := &component.Components{}
+	gateComps.Register(&GateComponent{})
+	gate := &cluster.Node{Options: options(gateComps), ServiceAddr: "127.0.0.1:15551"}
+	gate.ClientAddr = "127.0.0.1:15552"
+	c.Assert(gate.Startup(), IsNil)
+
+	gameComps := &component.Components{}
+	gameComps.Register(&GameComponent{})
+	game := &cluster.Node{Options: options(gameComps), ServiceAddr: "127.0.0.1:15553"}
+	c.Assert(game.Startup(), IsNil)
+
+	connector := io.NewConnector()
+	chWait := make(chan struct{})
+	connector.OnConnected(func() {
+		chWait <- struct{}{}
+	})
+	c.Assert(connector.Start("127.0.0.1:15552"), IsNil)
+	<-chWait
+
+	onResult := make(chan string)
+	connector.On("test", func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	c.Assert(connector.Notify("GameComponent.Test", &testdata.Ping{Content: "ping"}), IsNil)
+	c.Assert(strings.Contains(<-onResult, "game server pong"), IsTrue)
+
+	// Crashed member will be evicted after missing heartbeats
+	game.Halt()
+	waitNoRemoteService(c, master, "GameComponent")
+	waitNoRemoteService(c, gate, "GameComponent")
+
+	// The session bound to the crashed member should be re-routed
+	backupComps := &component.Components{}
+	backupComps.Register(&BackupComponent{}, component.WithName("GameComponent"))
+	backup := &cluster.Node{Options: options(backupComps), ServiceAddr: "127.0.0.1:15554"}
+	c.Assert(backup.Startup(), IsNil)
+	waitRemoteService(c, []*cluster.Node{gate}, "GameComponent")
+
+	c.Assert(connector.Notify("GameComponent.Test", &testdata.Ping{Content: "ping"}), IsNil)
+	c.Assert(strings.Contains(<-onResult, "backup server pong"), IsTrue)
+
+	backup.Shutdown()
+	gate.Shutdown()
+	master.Shutdown()
+}
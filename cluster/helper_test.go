@@ -0,0 +1,31 @@
+package cluster_test
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/client"
+)
+
+// retry calls fn until it succeeds, the client listener of node is started
+// asynchronously
+func retry(c *C, fn func() error) {
+	var err error
+	for i := 0; i < 50; i++ {
+		if err = fn(); err == nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(err, IsNil)
+}
+
+// dial connects a client to the node which is starting up
+func dial(c *C, addr string, opts ...client.Option) *client.Client {
+	var cl *client.Client
+	retry(c, func() (err error) {
+		cl, err = client.Dial(addr, opts...)
+		return err
+	})
+	return cl
+}
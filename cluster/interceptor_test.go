@@ -0,0 +1,96 @@
+package cluster_test
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/errors"
+	"github.com/revzim/amoeba/session"
+)
+
+type VaultComponent struct{ component.Base }
+
+func (c *VaultComponent) Open(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	return &testdata.Pong{Content: "opened " + ping.Content}, nil
+}
+
+func (c *VaultComponent) Peek(s *session.Session, ping *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: "peek " + ping.Content})
+}
+
+func (s *nodeSuite) TestInterceptor(c *C) {
+	var mu sync.Mutex
+	var trace []string
+	record := func(name string) component.Interceptor {
+		return func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+			mu.Lock()
+			trace = append(trace, name+" "+inv.Route)
+			mu.Unlock()
+			result, err := next(inv)
+			if pong, ok := result.(*testdata.Pong); ok {
+				pong.Content += " by " + name
+			}
+			return result, err
+		}
+	}
+	guard := func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+		switch inv.Arg.(*testdata.Ping).Content {
+		case "secret":
+			return nil, errors.New(401, "unauthorized")
+		case "forged":
+			inv.Arg = "forged"
+			return next(inv)
+		}
+		if inv.Context == nil || inv.Handler == nil || inv.ID == 0 {
+			return nil, errors.New(500, "incomplete invocation")
+		}
+		inv.Arg = &testdata.Ping{Content: "gold"}
+		return next(inv)
+	}
+	cache := func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+		return &testdata.Pong{Content: "cached"}, nil
+	}
+
+	comps := &component.Components{}
+	comps.Register(&VaultComponent{},
+		component.WithInterceptors(record("vault")),
+		component.WithRouteInterceptors("Open", guard),
+		component.WithRouteInterceptors("Peek", cache))
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: comps,
+			ClientAddr: "127.0.0.1:29551",
+		},
+		ServiceAddr: "127.0.0.1:29550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:29551")
+	defer c1.Close()
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "VaultComponent.Open", &testdata.Ping{Content: "silver"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "opened gold by vault")
+
+	err := c1.Call(context.Background(), "VaultComponent.Open", &testdata.Ping{Content: "secret"}, pong)
+	c.Assert(err, DeepEquals, errors.New(401, "unauthorized"))
+
+	// the argument of wrong type is a server fault rather than passed to handler
+	err = c1.Call(context.Background(), "VaultComponent.Open", &testdata.Ping{Content: "forged"}, pong)
+	c.Assert(err, NotNil)
+	c.Assert(errors.From(err, 0).Code, Equals, errors.CodeInternal)
+
+	// the handler is short-circuited and the result is sent as response
+	c.Assert(c1.Call(context.Background(), "VaultComponent.Peek", &testdata.Ping{Content: "gold"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "cached by vault")
+
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(trace, DeepEquals, []string{"vault VaultComponent.Open", "vault VaultComponent.Open", "vault VaultComponent.Open", "vault VaultComponent.Peek"})
+}
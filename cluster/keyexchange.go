@@ -0,0 +1,53 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"encoding/base64"
+
+	"github.com/revzim/amoeba/crypt"
+	"github.com/revzim/amoeba/session"
+)
+
+// exchangeKey derives the session cipher from the public key of client which
+// requested key exchange, the public key of current node will be set into sys.
+// A new key pair is generated for each handshake, so the resumed session gets
+// a new cipher
+func (n *Node) exchangeKey(req handshakeRequest, sys map[string]interface{}) (session.Cipher, error) {
+	if !n.KeyExchange || req.Sys.KeyExchange != crypt.X25519 {
+		return nil, nil
+	}
+	clientPublic, err := base64.StdEncoding.DecodeString(req.Sys.PublicKey)
+	if err != nil {
+		return nil, crypt.ErrInvalidPublicKey
+	}
+	kp, err := crypt.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	c, err := kp.ServerCipher(clientPublic)
+	if err != nil {
+		return nil, err
+	}
+	sys["keyExchange"] = crypt.X25519
+	sys["publicKey"] = base64.StdEncoding.EncodeToString(kp.Public)
+	return c, nil
+}
@@ -0,0 +1,145 @@
+package cluster_test
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/crypt"
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/session"
+)
+
+type CipherComponent struct{ component.Base }
+
+func (c *CipherComponent) Echo(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	return &testdata.Pong{Content: ping.Content}, nil
+}
+
+func (s *nodeSuite) TestKeyExchange(c *C) {
+	comps := &component.Components{}
+	comps.Register(&CipherComponent{})
+	pipe := pipeline.New()
+	crypt.InstallStages(pipe)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:31551",
+			Pipeline:    pipe,
+			KeyExchange: true,
+		},
+		ServiceAddr: "127.0.0.1:31550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	clientPipe := pipeline.New()
+	crypt.InstallStages(clientPipe)
+	c1 := dial(c, "127.0.0.1:31551", client.WithKeyExchange(), client.WithPipeline(clientPipe))
+	defer c1.Close()
+	c.Assert(c1.Session().Cipher(), NotNil)
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "CipherComponent.Echo", &testdata.Ping{Content: "secret"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "secret")
+
+	// the client without key exchange is refused in handshake, because its
+	// payloads could not be decrypted by the crypt stages
+	c2, err := client.Dial("127.0.0.1:31551")
+	c.Assert(err, Equals, client.ErrRejected)
+	c.Assert(c2, IsNil)
+}
+
+func (s *nodeSuite) TestKeyExchangeForward(c *C) {
+	// the gate runs the crypt stages only for the forwarded messages, and the
+	// backend runs its own inbound stages except the crypt stages
+	var gateInbound, backendInbound int32
+	counter := func(n *int32) pipeline.Func {
+		return func(_ *session.Session, _ *pipeline.Message) error {
+			atomic.AddInt32(n, 1)
+			return nil
+		}
+	}
+	pipe := pipeline.New()
+	crypt.InstallStages(pipe)
+	pipe.Inbound().PushBack(counter(&gateInbound))
+	backendPipe := pipeline.New()
+	crypt.InstallStages(backendPipe)
+	backendPipe.Inbound().PushBackNamed("auth", counter(&backendInbound))
+
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  &component.Components{},
+			ClientAddr:  "127.0.0.1:31557",
+			Pipeline:    pipe,
+			KeyExchange: true,
+		},
+		ServiceAddr: "127.0.0.1:31556",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	comps := &component.Components{}
+	comps.Register(&CipherComponent{})
+	backend := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:31556",
+			Components:    comps,
+			Pipeline:      backendPipe,
+		},
+		ServiceAddr: "127.0.0.1:31558",
+	}
+	c.Assert(backend.Startup(), IsNil)
+	defer backend.Shutdown()
+
+	clientPipe := pipeline.New()
+	crypt.InstallStages(clientPipe)
+	c1 := dial(c, "127.0.0.1:31557", client.WithKeyExchange(), client.WithPipeline(clientPipe))
+	defer c1.Close()
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "CipherComponent.Echo", &testdata.Ping{Content: "secret"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "secret")
+	c.Assert(atomic.LoadInt32(&gateInbound), Equals, int32(0))
+	c.Assert(atomic.LoadInt32(&backendInbound), Equals, int32(1))
+}
+
+func (s *nodeSuite) TestSequencedKeyExchange(c *C) {
+	comps := &component.Components{}
+	comps.Register(&CipherComponent{})
+	pipe := pipeline.New()
+	crypt.InstallSequencedStages(pipe, true)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:31571",
+			Pipeline:    pipe,
+			KeyExchange: true,
+		},
+		ServiceAddr: "127.0.0.1:31570",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	clientPipe := pipeline.New()
+	crypt.InstallSequencedStages(clientPipe, false)
+	c1 := dial(c, "127.0.0.1:31571", client.WithKeyExchange(), client.WithPipeline(clientPipe))
+	defer c1.Close()
+
+	pong := &testdata.Pong{}
+	for _, content := range []string{"first", "second"} {
+		c.Assert(c1.Call(context.Background(), "CipherComponent.Echo", &testdata.Ping{Content: content}, pong), IsNil)
+		c.Assert(pong.Content, Equals, content)
+	}
+
+	// the sequenced stages require key exchange as well
+	_, err := client.Dial("127.0.0.1:31571")
+	c.Assert(err, Equals, client.ErrRejected)
+}
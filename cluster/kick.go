@@ -0,0 +1,118 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/session"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// kickReason represents the data of kick packet
+type kickReason struct {
+	Reason string `json:"reason"`
+}
+
+// kickData returns the kick packet with the reason
+func kickData(reason string) ([]byte, error) {
+	data, err := json.Marshal(kickReason{Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(packet.Kick, data)
+}
+
+// Kick implements the MemberServer interface, the session of id will be kicked
+// if the session id is not zero, otherwise all sessions bound to the uid
+func (n *Node) Kick(_ context.Context, req *clusterpb.KickRequest) (*clusterpb.KickResponse, error) {
+	if req.SessionId == 0 {
+		n.kickUID(req.Uid, req.Reason)
+		return &clusterpb.KickResponse{}, nil
+	}
+
+	s := n.findSession(req.SessionId)
+	if s == nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", req.SessionId)
+	}
+	if err := s.Kick(req.Reason); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &clusterpb.KickResponse{}, nil
+}
+
+// KickUID kicks all client sessions bound to the uid in cluster, the kick will
+// be sent to all members, the sessions connected to gates will be kicked
+func (n *Node) KickUID(uid int64, reason string) error {
+	if uid < 1 {
+		return session.ErrIllegalUID
+	}
+	n.kickUID(uid, reason)
+
+	var lastErr error
+	request := &clusterpb.KickRequest{Uid: uid, Reason: reason}
+	for _, addr := range n.cluster.remoteAddrs() {
+		if addr == n.ServiceAddr {
+			continue
+		}
+		pool, err := n.rpcClient.getConnPool(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+		_, err = clusterpb.NewMemberClient(pool.Get()).Kick(ctx, request)
+		cancel()
+		if err != nil {
+			log.Println("Kick uid failed", addr, uid, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// kickUID kicks the client sessions bound to the uid which connected to current node
+func (n *Node) kickUID(uid int64, reason string) {
+	var sessions []*session.Session
+	n.RLock()
+	for _, s := range n.sessions {
+		if s.UID() != uid {
+			continue
+		}
+		if _, ok := gateAgent(s); ok {
+			sessions = append(sessions, s)
+		}
+	}
+	n.RUnlock()
+
+	for _, s := range sessions {
+		if err := s.Kick(reason); err != nil {
+			log.Printf("Session kick error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error())
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cluster_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+)
+
+type ModeratorComponent struct{ component.Base }
+
+func (c *ModeratorComponent) Login(s *session.Session, _ *testdata.Ping) error {
+	if err := s.Bind(1024); err != nil {
+		return err
+	}
+	return s.Response(&testdata.Pong{Content: "welcome"})
+}
+
+func (c *ModeratorComponent) Ban(s *session.Session, ping *testdata.Ping) error {
+	if err := s.Push("notice", &testdata.Pong{Content: "bye"}); err != nil {
+		return err
+	}
+	return s.Kick(ping.Content)
+}
+
+func (s *nodeSuite) TestKick(c *C) {
+	masterComps := &component.Components{}
+	masterComps.Register(&MasterComponent{})
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: masterComps},
+		ServiceAddr: "127.0.0.1:23550",
+	}
+	c.Assert(master.Startup(), IsNil)
+	defer master.Shutdown()
+
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:23550",
+			ClientAddr:    "127.0.0.1:23552",
+			Components:    &component.Components{},
+		},
+		ServiceAddr: "127.0.0.1:23551",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	moderatorComps := &component.Components{}
+	moderatorComps.Register(&ModeratorComponent{})
+	moderator := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:23550",
+			Components:    moderatorComps,
+		},
+		ServiceAddr: "127.0.0.1:23553",
+	}
+	c.Assert(moderator.Startup(), IsNil)
+	defer moderator.Shutdown()
+
+	// the pending push is sent before the kick packet
+	chNotice := make(chan string, 1)
+	c1, err := client.Dial("127.0.0.1:23552", client.WithPushHandler("notice", func(pong *testdata.Pong) {
+		chNotice <- pong.Content
+	}))
+	c.Assert(err, IsNil)
+	c.Assert(c1.Notify("ModeratorComponent.Ban", &testdata.Ping{Content: "banned"}), IsNil)
+	c.Assert(<-chNotice, Equals, "bye")
+	select {
+	case <-c1.Closed():
+	case <-time.After(3 * time.Second):
+		c.Fatal("kick timeout")
+	}
+	kick, ok := c1.Err().(*client.KickError)
+	c.Assert(ok, IsTrue, Commentf("unexpected error %v", c1.Err()))
+	c.Assert(kick.Reason, Equals, "banned")
+
+	c2, err := client.Dial("127.0.0.1:23552")
+	c.Assert(err, IsNil)
+	pong := &testdata.Pong{}
+	c.Assert(c2.Call(context.Background(), "ModeratorComponent.Login", &testdata.Ping{}, pong), IsNil)
+	c.Assert(moderator.KickUID(1024, "duplicated login"), IsNil)
+	select {
+	case <-c2.Closed():
+	case <-time.After(3 * time.Second):
+		c.Fatal("kick uid timeout")
+	}
+	kick, ok = c2.Err().(*client.KickError)
+	c.Assert(ok, IsTrue, Commentf("unexpected error %v", c2.Err()))
+	c.Assert(kick.Reason, Equals, "duplicated login")
+}
@@ -20,11 +20,16 @@
 
 package cluster
 
-import "github.com/revzim/amoeba/cluster/clusterpb"
+import (
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+)
 
 type Member struct {
-	isMaster   bool
-	memberInfo *clusterpb.MemberInfo
+	isMaster      bool
+	memberInfo    *clusterpb.MemberInfo
+	lastHeartbeat time.Time // only maintained by the leader of master nodes
 }
 
 func (m *Member) MemberInfo() *clusterpb.MemberInfo {
@@ -38,23 +38,47 @@ import (
 	"github.com/revzim/amoeba/internal/log"
 	"github.com/revzim/amoeba/internal/message"
 	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/ratelimit"
 	"github.com/revzim/amoeba/scheduler"
+	"github.com/revzim/amoeba/serialize"
 	"github.com/revzim/amoeba/session"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Options contains some configurations for current node
 type Options struct {
-	Pipeline       pipeline.Pipeline
-	IsMaster       bool
-	AdvertiseAddr  string
-	RetryInterval  time.Duration
-	ClientAddr     string
-	Components     *component.Components
-	Label          string
-	IsWebsocket    bool
-	TSLCertificate string
-	TSLKey         string
+	Pipeline            pipeline.Pipeline
+	IsMaster            bool
+	AdvertiseAddr       string
+	Masters             []string      // service addresses of all master nodes
+	ElectionTimeout     time.Duration // leader election timeout of master nodes
+	RetryInterval       time.Duration
+	StreamTransport     bool              // forward messages between members over bidirectional streams
+	PushBatchWindow     time.Duration     // coalesce pushes to gate within the window, disabled if zero
+	ResumeGrace         time.Duration     // keep the session of broken connection for resume, disabled if zero
+	Compression         []string          // packet compression algorithms enabled for clients, see CompressDeflate and CompressZstd
+	CompressThreshold   int               // packets shorter than threshold will not be compressed
+	CompressDict        []byte            // shared dictionary of deflate compression
+	MaxReassemblySize   int               // max reassembly memory of each connection, codec.DefaultMaxReassemblySize if zero
+	ReassemblyTimeout   time.Duration     // fragments of a packet should be received within timeout, codec.DefaultReassemblyTimeout if zero
+	AutoDictionary      bool              // generate route dictionary from handlers and push routes
+	DictionaryLock      string            // file which records the generated codes to keep them stable, shared by all nodes accepting clients
+	PushRoutes          []string          // push routes which will be added into generated dictionary
+	ClientVersions      map[string]string // minimum versions of client types, the outdated and untyped clients will be rejected
+	Capabilities        []string          // capabilities supported by current node, negotiated with clients
+	HandshakeHandler    HandshakeHandler  // returns the user data of handshake response
+	KeyExchange         bool              // negotiate session ciphers with the clients which request key exchange
+	MemberHeartbeat     time.Duration     // heartbeat interval of members to master nodes
+	MaxMissedHeartbeats int               // members missed heartbeats will be evicted
+	RateLimit           *ratelimit.Config // rate limits of client messages, disabled if nil
+	ClientAddr          string
+	Components          *component.Components
+	Label               string
+	IsWebsocket         bool
+	TSLCertificate      string
+	TSLKey              string
 }
 
 // Node represents a node in amoeba cluster, which will contains a group of services.
@@ -69,8 +93,15 @@ type Node struct {
 	handler   *LocalHandler
 	server    *grpc.Server
 	rpcClient *rpcClient
+	streamer  *streamer
+	resumer   *resumer
 
 	sessions map[int64]*session.Session
+	pushers  map[string]*pushBatcher              // gate address => push batcher
+	backlogs map[int64][]*clusterpb.StreamMessage // session id => stream messages waiting for session
+	chDie    chan struct{}
+
+	onMemberRemoved []func(addr string) // callbacks emitted once a member removed from cluster
 
 	// mongoDriver    *drivers.AZMongoApp
 	// firebaseDriver *drivers.AZFirebaseApp
@@ -81,8 +112,10 @@ func (n *Node) Startup() error {
 		return errors.New("service address cannot be empty in master node")
 	}
 	n.sessions = map[int64]*session.Session{}
+	n.chDie = make(chan struct{})
 	n.cluster = newCluster(n)
 	n.handler = NewHandler(n, n.Pipeline)
+	n.resumer = newResumer(n.handler, n.ResumeGrace)
 	components := n.Components.List()
 	for _, c := range components {
 		err := n.handler.register(c.Comp, c.Opts)
@@ -91,6 +124,9 @@ func (n *Node) Startup() error {
 		}
 	}
 
+	if err := n.initDictionary(); err != nil {
+		return err
+	}
 	cache()
 	if err := n.initNode(); err != nil {
 		return err
@@ -136,6 +172,9 @@ func (n *Node) InitSansServer() error {
 		}
 	}
 
+	if err := n.initDictionary(); err != nil {
+		return err
+	}
 	cache()
 
 	// NON SINGLETON MODE
@@ -212,7 +251,7 @@ func (n *Node) Handler() *LocalHandler {
 func (n *Node) initNode() error {
 	// Current node is not master server and does not contains master
 	// address, so running in singleton mode
-	if !n.IsMaster && n.AdvertiseAddr == "" {
+	if !n.IsMaster && n.AdvertiseAddr == "" && len(n.Masters) == 0 {
 		return nil
 	}
 
@@ -224,57 +263,100 @@ func (n *Node) initNode() error {
 	// Initialize the gRPC server and register service
 	n.server = grpc.NewServer()
 	n.rpcClient = newRPCClient()
+	n.streamer = newStreamer(n)
 	clusterpb.RegisterMemberServer(n.server, n)
+	if n.IsMaster {
+		clusterpb.RegisterMasterServer(n.server, n.cluster)
+	}
 
 	go func() {
+		// the node may be shut down before serving
 		err := n.server.Serve(listener)
-		if err != nil {
+		if err != nil && err != grpc.ErrServerStopped {
 			log.Fatalf("Start current node failed: %v", err)
 		}
 	}()
 
 	if n.IsMaster {
-		clusterpb.RegisterMasterServer(n.server, n.cluster)
 		member := &Member{
-			isMaster: true,
-			memberInfo: &clusterpb.MemberInfo{
-				Label:       n.Label,
-				ServiceAddr: n.ServiceAddr,
-				Services:    n.handler.LocalService(),
-			},
+			isMaster:   true,
+			memberInfo: n.memberInfo(),
 		}
 		n.cluster.members = append(n.cluster.members, member)
 		n.cluster.setRpcClient(n.rpcClient)
+		n.cluster.initElection(n.Masters)
 		// log.Println("init rpc server - ", member.memberInfo.Label)
 	} else {
-		pool, err := n.rpcClient.getConnPool(n.AdvertiseAddr)
-		if err != nil {
-			return err
-		}
-		client := clusterpb.NewMasterClient(pool.Get())
 		request := &clusterpb.RegisterRequest{
-			MemberInfo: &clusterpb.MemberInfo{
-				Label:       n.Label,
-				ServiceAddr: n.ServiceAddr,
-				Services:    n.handler.LocalService(),
-			},
+			MemberInfo: n.memberInfo(),
 		}
-		for {
-			resp, err := client.Register(context.Background(), request)
+
+		// Try all known master nodes in turn, any master will forward the
+		// request to the leader
+		masters := n.masterCandidates()
+		for i := 0; ; i++ {
+			addr := masters[i%len(masters)]
+			resp, err := n.register(addr, request)
 			if err == nil {
 				n.handler.initRemoteService(resp.Members)
 				n.cluster.initMembers(resp.Members)
+				if len(resp.Masters) > 0 {
+					n.cluster.setMasters(resp.Masters)
+				} else {
+					n.cluster.setMasters(masters)
+				}
 				break
 			}
 			log.Println("Register current node to cluster failed", err, "and will retry in", n.RetryInterval.String())
 			time.Sleep(n.RetryInterval)
 		}
-
 	}
 
+	go n.keepalive()
 	return nil
 }
 
+// memberInfo returns the member information of current node
+func (n *Node) memberInfo() *clusterpb.MemberInfo {
+	return &clusterpb.MemberInfo{
+		Label:       n.Label,
+		ServiceAddr: n.ServiceAddr,
+		Services:    n.handler.LocalService(),
+		Balancers:   n.handler.LocalBalancers(),
+		Routes:      n.handler.LocalRoutes(),
+		Serializers: n.handler.LocalSerializers(),
+	}
+}
+
+// masterCandidates returns the addresses which current node can register to
+func (n *Node) masterCandidates() []string {
+	var masters []string
+	if n.AdvertiseAddr != "" {
+		masters = append(masters, n.AdvertiseAddr)
+	}
+	for _, addr := range n.Masters {
+		if addr != n.AdvertiseAddr {
+			masters = append(masters, addr)
+		}
+	}
+	return masters
+}
+
+func (n *Node) register(addr string, request *clusterpb.RegisterRequest) (*clusterpb.RegisterResponse, error) {
+	pool, err := n.rpcClient.getConnPool(addr)
+	if err != nil {
+		return nil, err
+	}
+	client := clusterpb.NewMasterClient(pool.Get())
+	return client.Register(context.Background(), request)
+}
+
+// Leader returns the leader address of master nodes, which is only available
+// in master node
+func (n *Node) Leader() string {
+	return n.cluster.leaderAddr()
+}
+
 // Shutdowns all components registered by application, that
 // call by reverse order against register
 func (n *Node) Shutdown() {
@@ -290,24 +372,47 @@ func (n *Node) Shutdown() {
 		components[i].Comp.Shutdown()
 	}
 
-	if !n.IsMaster && n.AdvertiseAddr != "" {
-		pool, err := n.rpcClient.getConnPool(n.AdvertiseAddr)
-		if err != nil {
-			log.Println("Retrieve master address error", err)
-			goto EXIT
+	n.RLock()
+	for _, b := range n.pushers {
+		b.close()
+	}
+	n.RUnlock()
+
+	if n.chDie != nil {
+		select {
+		case <-n.chDie:
+		default:
+			close(n.chDie)
 		}
-		client := clusterpb.NewMasterClient(pool.Get())
+	}
+	if n.IsMaster {
+		n.cluster.stopElection()
+	} else if n.AdvertiseAddr != "" || len(n.Masters) > 0 {
 		request := &clusterpb.UnregisterRequest{
 			ServiceAddr: n.ServiceAddr,
 		}
-		_, err = client.Unregister(context.Background(), request)
+		var err error
+		for _, addr := range n.cluster.masterAddrs() {
+			var pool *connPool
+			pool, err = n.rpcClient.getConnPool(addr)
+			if err != nil {
+				log.Println("Retrieve master address error", err)
+				continue
+			}
+			client := clusterpb.NewMasterClient(pool.Get())
+			_, err = client.Unregister(context.Background(), request)
+			if err == nil {
+				break
+			}
+		}
 		if err != nil {
 			log.Println("Unregister current node failed", err)
-			goto EXIT
 		}
 	}
 
-EXIT:
+	if n.streamer != nil {
+		n.streamer.close()
+	}
 	if n.server != nil {
 		n.server.GracefulStop()
 	}
@@ -409,6 +514,12 @@ func (n *Node) storeSession(s *session.Session) {
 	n.Unlock()
 }
 
+func (n *Node) removeSession(sid int64) {
+	n.Lock()
+	delete(n.sessions, sid)
+	n.Unlock()
+}
+
 func (n *Node) findSession(sid int64) *session.Session {
 	n.RLock()
 	s := n.sessions[sid]
@@ -420,27 +531,87 @@ func (n *Node) findOrCreateSession(sid int64, gateAddr string) (*session.Session
 	n.RLock()
 	s, found := n.sessions[sid]
 	n.RUnlock()
-	if !found {
-		conns, err := n.rpcClient.getConnPool(gateAddr)
-		if err != nil {
-			return nil, err
-		}
-		ac := &acceptor{
-			sid:        sid,
-			gateClient: clusterpb.NewMemberClient(conns.Get()),
-			rpcHandler: n.handler.remoteProcess,
-			gateAddr:   gateAddr,
-		}
-		s = session.New(ac)
-		ac.session = s
-		n.Lock()
-		n.sessions[sid] = s
-		n.Unlock()
+	if found {
+		return s, nil
+	}
+
+	conns, err := n.rpcClient.getConnPool(gateAddr)
+	if err != nil {
+		return nil, err
+	}
+	ac := &acceptor{
+		sid:        sid,
+		gateClient: clusterpb.NewMemberClient(conns.Get()),
+		rpcHandler: n.handler.remoteProcess,
+		rpcCaller:  n.handler.remoteCall,
+		gateAddr:   gateAddr,
+		nodeAddr:   n.ServiceAddr,
+
+		serializerOf: n.handler.serializerOf,
 	}
+	ac.states = newStateQueue(ac.syncState)
+	// The messages over stream are ordered by the stream itself
+	if n.StreamTransport {
+		ac.streamer = n.streamer
+	} else {
+		ac.pusher = n.pushBatcher(gateAddr, conns)
+	}
+	s = session.New(ac)
+	ac.session = s
+	n.fetchState(s, ac)
+
+	n.Lock()
+	defer n.Unlock()
+	// The session may be created by other request during fetching
+	if exists, found := n.sessions[sid]; found {
+		return exists, nil
+	}
+	n.sessions[sid] = s
 	return s, nil
 }
 
-func (n *Node) HandleRequest(_ context.Context, req *clusterpb.RequestMessage) (*clusterpb.MemberHandleResponse, error) {
+// pushBatcher returns the push batcher of gate, nil will be returned if push
+// batch is disabled
+func (n *Node) pushBatcher(gateAddr string, conns *connPool) *pushBatcher {
+	if n.PushBatchWindow <= 0 {
+		return nil
+	}
+	n.Lock()
+	defer n.Unlock()
+	if n.pushers == nil {
+		n.pushers = map[string]*pushBatcher{}
+	}
+	b, found := n.pushers[gateAddr]
+	if !found {
+		b = newPushBatcher(clusterpb.NewMemberClient(conns.Get()), n.PushBatchWindow)
+		n.pushers[gateAddr] = b
+	}
+	return b
+}
+
+// fetchState fetches the session state from gate, the session is still usable
+// with empty state if fetching failed
+func (n *Node) fetchState(s *session.Session, ac *acceptor) {
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	request := &clusterpb.FetchSessionRequest{
+		SessionId:   ac.sid,
+		ServiceAddr: n.ServiceAddr,
+	}
+	resp, err := ac.gateClient.FetchSession(ctx, request)
+	if err != nil {
+		log.Println("Fetch session state from gate failed", ac.gateAddr, err)
+		return
+	}
+	delta, err := decodeState(resp.Data)
+	if err != nil {
+		log.Println("Decode session state failed", err)
+		return
+	}
+	applyState(s, resp.Uid, delta)
+}
+
+func (n *Node) HandleRequest(ctx context.Context, req *clusterpb.RequestMessage) (*clusterpb.MemberHandleResponse, error) {
 	handler, found := n.handler.localHandlers[req.Route]
 	if !found {
 		return nil, fmt.Errorf("service not found in current node: %v", req.Route)
@@ -455,11 +626,74 @@ func (n *Node) HandleRequest(_ context.Context, req *clusterpb.RequestMessage) (
 		Route: req.Route,
 		Data:  req.Data,
 	}
-	n.handler.localProcess(handler, req.Id, s, msg)
+	n.handler.localProcess(ctx, handler, req.Id, s, msg, req.Metadata)
 	return &clusterpb.MemberHandleResponse{}, nil
 }
 
-func (n *Node) HandleNotify(_ context.Context, req *clusterpb.NotifyMessage) (*clusterpb.MemberHandleResponse, error) {
+// HandleCall implements the MemberServer interface, the request will be processed
+// by local handler and the reply will be returned after the handler responded
+func (n *Node) HandleCall(ctx context.Context, req *clusterpb.CallRequest) (*clusterpb.CallResponse, error) {
+	handler, found := n.handler.localHandlers[req.Route]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "service not found in current node: %v", req.Route)
+	}
+
+	var s *session.Session
+	if req.SessionId == 0 {
+		// Call started by node itself, which does not relate to any client session
+		ac := &acceptor{
+			rpcHandler: n.handler.remoteProcess,
+			rpcCaller:  n.handler.remoteCall,
+
+			serializerOf: n.handler.serializerOf,
+		}
+		s = session.New(ac)
+		ac.session = s
+	} else {
+		var err error
+		s, err = n.findOrCreateSession(req.SessionId, req.GateAddr)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+
+	ac := s.NetworkEntity().(*acceptor)
+	mid, chResult := ac.beginCall()
+	defer ac.finishCall(mid, callResult{})
+
+	msg := &message.Message{
+		Type:  message.Request,
+		ID:    mid,
+		Route: req.Route,
+		Data:  req.Data,
+	}
+	n.handler.localProcess(ctx, handler, mid, s, msg, req.Metadata)
+
+	select {
+	case r := <-chResult:
+		return callReply(r)
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// Call sends a request to the remote service which provides the route and waits
+// for the reply, the reply will be unmarshaled into reply. A default deadline
+// will be applied if ctx does not carry one
+func (n *Node) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	serializer := n.handler.serializerOf(route)
+	data, err := message.SerializeWith(serializer, v)
+	if err != nil {
+		return err
+	}
+	res, err := n.handler.remoteCall(ctx, nil, route, data)
+	if err != nil {
+		return err
+	}
+	return message.DeserializeWith(serializer, res, reply)
+}
+
+func (n *Node) HandleNotify(ctx context.Context, req *clusterpb.NotifyMessage) (*clusterpb.MemberHandleResponse, error) {
 	handler, found := n.handler.localHandlers[req.Route]
 	if !found {
 		return nil, fmt.Errorf("service not found in current node: %v", req.Route)
@@ -473,7 +707,7 @@ func (n *Node) HandleNotify(_ context.Context, req *clusterpb.NotifyMessage) (*c
 		Route: req.Route,
 		Data:  req.Data,
 	}
-	n.handler.localProcess(handler, 0, s, msg)
+	n.handler.localProcess(ctx, handler, 0, s, msg, req.Metadata)
 	return &clusterpb.MemberHandleResponse{}, nil
 }
 
@@ -485,7 +719,100 @@ func (n *Node) HandlePush(_ context.Context, req *clusterpb.PushMessage) (*clust
 	return &clusterpb.MemberHandleResponse{}, s.Push(req.Route, req.Data)
 }
 
+// HandleMulticast implements the MemberServer interface
+func (n *Node) HandleMulticast(_ context.Context, req *clusterpb.MulticastMessage) (*clusterpb.MemberHandleResponse, error) {
+	n.multicast(req.SessionIds, req.Route, req.Data)
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+// multicast pushes the message to the sessions connected to current node, the
+// sessions which have been closed will be ignored
+func (n *Node) multicast(sids []int64, route string, data []byte) {
+	for _, sid := range sids {
+		s := n.findSession(sid)
+		if s == nil {
+			continue
+		}
+		if err := s.Push(route, data); err != nil {
+			log.Printf("Session push message error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error())
+		}
+	}
+}
+
+// Multicast pushes the serialized message to the sessions connected to the
+// gate, the gate will push it to all sessions with only one rpc. The message
+// is sent after the messages of sessions sent to the gate before, over the
+// member stream or the push batcher if enabled
+func (n *Node) Multicast(gateAddr string, sids []int64, route string, data []byte) error {
+	if gateAddr == n.ServiceAddr {
+		n.multicast(sids, route, data)
+		return nil
+	}
+	request := &clusterpb.MulticastMessage{
+		SessionIds: sids,
+		Route:      route,
+		Data:       data,
+	}
+	if n.StreamTransport {
+		return n.streamer.send(gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Multicast{Multicast: request},
+		})
+	}
+	pool, err := n.rpcClient.getConnPool(gateAddr)
+	if err != nil {
+		return err
+	}
+	if b := n.pushBatcher(gateAddr, pool); b != nil {
+		return b.send(0, func(ctx context.Context, client clusterpb.MemberClient) error {
+			_, err := client.HandleMulticast(ctx, request)
+			return err
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	_, err = clusterpb.NewMemberClient(pool.Get()).HandleMulticast(ctx, request)
+	return err
+}
+
+// SerializerOf returns the serializer of the route provided by current node,
+// nil will be returned if the route uses the default serializer
+func (n *Node) SerializerOf(route string) serialize.Serializer {
+	return n.handler.serializerOf(route)
+}
+
+// OnMemberRemoved registers the callback which will be called with the service
+// address of member once the member removed from cluster, e.g: evicted for
+// missed heartbeats
+func (n *Node) OnMemberRemoved(h func(addr string)) {
+	n.Lock()
+	defer n.Unlock()
+	n.onMemberRemoved = append(n.onMemberRemoved, h)
+}
+
+// SessionOrigin returns the gate address and the session id in gate of the
+// session, which identifies a client session in cluster
+func (n *Node) SessionOrigin(s *session.Session) (string, int64) {
+	return n.handler.sessionOrigin(s)
+}
+
+// HandlePushBatch implements the MemberServer interface
+func (n *Node) HandlePushBatch(_ context.Context, req *clusterpb.PushBatch) (*clusterpb.MemberHandleResponse, error) {
+	for _, push := range req.Pushes {
+		s := n.findSession(push.SessionId)
+		if s == nil {
+			continue
+		}
+		if err := s.Push(push.Route, push.Data); err != nil {
+			log.Printf("Session push message error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error())
+		}
+	}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
 func (n *Node) HandleResponse(_ context.Context, req *clusterpb.ResponseMessage) (*clusterpb.MemberHandleResponse, error) {
+	if req.Error {
+		return &clusterpb.MemberHandleResponse{}, n.deliver(req.SessionId, pendingMessage{typ: message.Response, mid: req.Id, payload: req.Data, isError: true})
+	}
 	s := n.findSession(req.SessionId)
 	if s == nil {
 		return &clusterpb.MemberHandleResponse{}, fmt.Errorf("session not found: %v", req.SessionId)
@@ -500,8 +827,7 @@ func (n *Node) NewMember(_ context.Context, req *clusterpb.NewMemberRequest) (*c
 }
 
 func (n *Node) DelMember(_ context.Context, req *clusterpb.DelMemberRequest) (*clusterpb.DelMemberResponse, error) {
-	n.handler.delMember(req.ServiceAddr)
-	n.cluster.delMember(req.ServiceAddr)
+	n.delMember(req.ServiceAddr)
 	return &clusterpb.DelMemberResponse{}, nil
 }
 
@@ -512,6 +838,8 @@ func (n *Node) SessionClosed(_ context.Context, req *clusterpb.SessionClosedRequ
 	delete(n.sessions, req.SessionId)
 	n.Unlock()
 	if found {
+		n.handler.unbindSession(s, "")
+		n.handler.limiter.Forget(s.ID(), s.UID())
 		scheduler.PushTask(func() { session.Lifetime.Close(s) })
 	}
 	return &clusterpb.SessionClosedResponse{}, nil
@@ -528,3 +856,47 @@ func (n *Node) CloseSession(_ context.Context, req *clusterpb.CloseSessionReques
 	}
 	return &clusterpb.CloseSessionResponse{}, nil
 }
+
+// FetchSession implements the MemberServer interface
+func (n *Node) FetchSession(_ context.Context, req *clusterpb.FetchSessionRequest) (*clusterpb.FetchSessionResponse, error) {
+	s := n.findSession(req.SessionId)
+	if s == nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", req.SessionId)
+	}
+	a, ok := gateAgent(s)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %v is not connected to current node", req.SessionId)
+	}
+	data, err := encodeState(session.Delta{Values: s.State(), UIDChanged: true})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	a.subscribe(req.ServiceAddr)
+	return &clusterpb.FetchSessionResponse{Uid: s.UID(), Data: data}, nil
+}
+
+// SyncSession implements the MemberServer interface
+func (n *Node) SyncSession(_ context.Context, req *clusterpb.SyncSessionRequest) (*clusterpb.SyncSessionResponse, error) {
+	s := n.findSession(req.SessionId)
+	if s == nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", req.SessionId)
+	}
+	delta, err := decodeState(req.Data)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	applyState(s, req.Uid, delta)
+
+	// Gate forwards the changes to other backend nodes
+	if a, ok := gateAgent(s); ok {
+		a.subscribe(req.ServiceAddr)
+		a.syncPeers(req.ServiceAddr, req.Uid, delta)
+	}
+	return &clusterpb.SyncSessionResponse{}, nil
+}
+
+// RateLimitViolations returns the counters of the client messages exceeded the
+// rate limits on current node
+func (n *Node) RateLimitViolations() ratelimit.Violations {
+	return n.handler.limiter.Violations()
+}
@@ -1,14 +1,19 @@
 package cluster_test
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/balancer"
 	"github.com/revzim/amoeba/benchmark/io"
 	"github.com/revzim/amoeba/benchmark/testdata"
 	"github.com/revzim/amoeba/cluster"
 	"github.com/revzim/amoeba/component"
+	amoebaerrors "github.com/revzim/amoeba/errors"
 	"github.com/revzim/amoeba/scheduler"
 	"github.com/revzim/amoeba/session"
 )
@@ -35,6 +40,18 @@ func (c *GateComponent) Test2(session *session.Session, ping *testdata.Ping) err
 	return session.Response(&testdata.Pong{Content: "gate server pong2"})
 }
 
+func (c *GateComponent) Proxy(session *session.Session, ping *testdata.Ping) error {
+	mid := session.LastMid()
+	go func() {
+		pong := &testdata.Pong{}
+		if err := session.Call(context.Background(), "GameComponent.Test2", ping, pong); err != nil {
+			pong.Content = err.Error()
+		}
+		session.ResponseMID(mid, &testdata.Pong{Content: "proxy " + pong.Content})
+	}()
+	return nil
+}
+
 func (c *GameComponent) Test(session *session.Session, _ []byte) error {
 	return session.Push("test", &testdata.Pong{Content: "game server pong"})
 }
@@ -43,14 +60,27 @@ func (c *GameComponent) Test2(session *session.Session, ping *testdata.Ping) err
 	return session.Response(&testdata.Pong{Content: "game server pong2"})
 }
 
+func (c *GameComponent) Fail(session *session.Session, ping *testdata.Ping) error {
+	return errors.New("game server failed")
+}
+
+func (c *GameComponent) Silent(session *session.Session, ping *testdata.Ping) error {
+	return nil
+}
+
 func TestNode(t *testing.T) {
 	TestingT(t)
 }
 
-func (s *nodeSuite) TestNodeStartup(c *C) {
+func (s *nodeSuite) SetUpSuite(c *C) {
 	go scheduler.Sched()
-	defer scheduler.Close()
+}
+
+func (s *nodeSuite) TearDownSuite(c *C) {
+	scheduler.Close()
+}
 
+func (s *nodeSuite) TestNodeStartup(c *C) {
 	masterComps := &component.Components{}
 	masterComps.Register(&MasterComponent{})
 	masterNode := &cluster.Node{
@@ -141,4 +171,40 @@ func (s *nodeSuite) TestNodeStartup(c *C) {
 	err = connector.Notify("MasterComponent.Test", &testdata.Ping{Content: "ping"})
 	c.Assert(err, IsNil)
 	c.Assert(strings.Contains(<-onResult, "master server pong"), IsTrue)
+
+	err = connector.Request("GateComponent.Proxy", &testdata.Ping{Content: "ping"}, func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(<-onResult, "proxy game server pong2"), IsTrue)
+
+	pong := &testdata.Pong{}
+	err = memberNode1.Call(context.Background(), "GameComponent.Test2", &testdata.Ping{Content: "ping"}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "game server pong2")
+
+	err = masterNode.Call(context.Background(), "GameComponent.Fail", &testdata.Ping{Content: "ping"}, pong)
+	c.Assert(err, NotNil)
+	c.Assert(amoebaerrors.From(err, 0).Code, Equals, amoebaerrors.CodeInternal)
+	c.Assert(amoebaerrors.From(err, 0).Message, Equals, "game server failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = memberNode1.Call(ctx, "GameComponent.Silent", &testdata.Ping{Content: "ping"}, pong)
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "DeadlineExceeded"), IsTrue)
+
+	err = memberNode1.Call(context.Background(), "UnknownComponent.Test", &testdata.Ping{Content: "ping"}, pong)
+	c.Assert(err, Equals, cluster.ErrRouteNotFound)
+}
+
+func (s *nodeSuite) TestUnknownBalancer(c *C) {
+	comps := &component.Components{}
+	comps.Register(&GameComponent{}, component.WithBalancer("unknown"))
+	node := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: comps},
+		ServiceAddr: "127.0.0.1:31563",
+	}
+	err := node.Startup()
+	c.Assert(errors.Is(err, balancer.ErrUnknownBalancer), IsTrue, Commentf("%v", err))
 }
@@ -0,0 +1,80 @@
+package cluster_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/errors"
+	"github.com/revzim/amoeba/ratelimit"
+	"github.com/revzim/amoeba/session"
+)
+
+type ThrottleComponent struct{ component.Base }
+
+func (c *ThrottleComponent) Ping(s *session.Session, _ *testdata.Ping) (*testdata.Pong, error) {
+	return &testdata.Pong{Content: "pong"}, nil
+}
+
+func (c *ThrottleComponent) Pong(s *session.Session, _ *testdata.Ping) (*testdata.Pong, error) {
+	return &testdata.Pong{Content: "ping"}, nil
+}
+
+func startThrottleNode(c *C, serviceAddr, clientAddr string, config ratelimit.Config) (*cluster.Node, *client.Client) {
+	comps := &component.Components{}
+	comps.Register(&ThrottleComponent{}, component.WithRouteRateLimit("Pong", ratelimit.Limit{Rate: 0.001, Burst: 1}))
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: comps,
+			ClientAddr: clientAddr,
+			RateLimit:  &config,
+		},
+		ServiceAddr: serviceAddr,
+	}
+	c.Assert(node.Startup(), IsNil)
+
+	return node, dial(c, clientAddr)
+}
+
+func (s *nodeSuite) TestRateLimit(c *C) {
+	node, c1 := startThrottleNode(c, "127.0.0.1:30550", "127.0.0.1:30551", ratelimit.Config{
+		Routes: map[string]ratelimit.Limit{"ThrottleComponent.Ping": {Rate: 0.001, Burst: 2}},
+	})
+	defer node.Shutdown()
+	defer c1.Close()
+
+	call := func(route string) error {
+		return c1.Call(context.Background(), route, &testdata.Ping{}, &testdata.Pong{})
+	}
+	c.Assert(call("ThrottleComponent.Ping"), IsNil)
+	c.Assert(call("ThrottleComponent.Ping"), IsNil)
+	c.Assert(call("ThrottleComponent.Ping"), DeepEquals, errors.New(errors.CodeRateLimited, cluster.ErrRateLimited.Error()))
+	c.Assert(call("ThrottleComponent.Pong"), IsNil)
+	c.Assert(call("ThrottleComponent.Pong"), DeepEquals, errors.New(errors.CodeRateLimited, cluster.ErrRateLimited.Error()))
+
+	v := node.RateLimitViolations()
+	c.Assert(v.Routes, DeepEquals, map[string]uint64{"ThrottleComponent.Ping": 1, "ThrottleComponent.Pong": 1})
+
+	// the session exceeded limit is kicked
+	kicker, c2 := startThrottleNode(c, "127.0.0.1:30552", "127.0.0.1:30553", ratelimit.Config{
+		Session: ratelimit.Limit{Rate: 0.001, Burst: 1},
+		Policy:  ratelimit.Kick,
+	})
+	defer kicker.Shutdown()
+	defer c2.Close()
+
+	c.Assert(c2.Call(context.Background(), "ThrottleComponent.Ping", &testdata.Ping{}, &testdata.Pong{}), IsNil)
+	c.Assert(c2.Notify("ThrottleComponent.Ping", &testdata.Ping{}), IsNil)
+	select {
+	case <-c2.Closed():
+	case <-time.After(time.Second):
+		c.Fatal("session should be kicked")
+	}
+	c.Assert(c2.Err(), DeepEquals, &client.KickError{Reason: cluster.ErrRateLimited.Error()})
+	c.Assert(kicker.RateLimitViolations().Session, Equals, uint64(1))
+}
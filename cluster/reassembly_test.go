@@ -0,0 +1,45 @@
+package cluster_test
+
+import (
+	"net"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/packet"
+)
+
+func (s *nodeSuite) TestReassemblyTimeout(c *C) {
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:          true,
+			Components:        &component.Components{},
+			ClientAddr:        "127.0.0.1:31555",
+			ReassemblyTimeout: 100 * time.Millisecond,
+		},
+		ServiceAddr: "127.0.0.1:31554",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	var conn net.Conn
+	retry(c, func() (err error) {
+		conn, err = net.Dial("tcp", "127.0.0.1:31555")
+		return err
+	})
+	defer conn.Close()
+
+	// the peer stops sending after the first fragment
+	p, err := codec.Encode(packet.Data, make([]byte, 2*codec.MaxPacketSize))
+	c.Assert(err, IsNil)
+	_, err = conn.Write(p[:codec.MaxPacketSize+codec.HeadLength])
+	c.Assert(err, IsNil)
+
+	c.Assert(conn.SetReadDeadline(time.Now().Add(3*time.Second)), IsNil)
+	_, err = conn.Read(make([]byte, 16))
+	c.Assert(err, NotNil)
+	netErr, ok := err.(net.Error)
+	c.Assert(ok && netErr.Timeout(), IsFalse, Commentf("connection not closed by reassembly timeout"))
+}
@@ -0,0 +1,107 @@
+package cluster_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/crypt"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/pipeline"
+)
+
+func (s *nodeSuite) TestSequencedResume(c *C) {
+	secret := crypt.New([]byte("secret"), 0)
+	pipe := pipeline.New()
+	secret.InstallSequenceStages(pipe, true)
+
+	comps := &component.Components{}
+	comps.Register(&CipherComponent{})
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:31553",
+			Pipeline:    pipe,
+			ResumeGrace: time.Second,
+		},
+		ServiceAddr: "127.0.0.1:31552",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	clientPipe := pipeline.New()
+	secret.InstallSequenceStages(clientPipe, false)
+	c1 := dial(c, "127.0.0.1:31553", client.WithPipeline(clientPipe))
+
+	echo := func(cl *client.Client, content string) {
+		pong := &testdata.Pong{}
+		c.Assert(cl.Call(context.Background(), "CipherComponent.Echo", &testdata.Ping{Content: content}, pong), IsNil)
+		c.Assert(pong.Content, Equals, content)
+	}
+	echo(c1, "first")
+	echo(c1, "second")
+
+	// the sequences of both directions restart on the resumed connection, and
+	// the messages are bound to the nonce of new connection
+	c1.Close()
+	for i := 0; i < 50 && node.DetachedSessions() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c2, err := client.Dial("127.0.0.1:31553", client.WithPipeline(clientPipe), client.WithResumeToken(c1.ResumeToken()))
+	c.Assert(err, IsNil)
+	defer c2.Close()
+	c.Assert(c2.Resumed(), IsTrue)
+	c.Assert(c2.Session().Nonce(), HasLen, 32)
+	c.Assert(c2.Session().Nonce(), Not(DeepEquals), c1.Session().Nonce())
+	echo(c2, "resumed")
+}
+
+func (s *nodeSuite) TestSequencedWithoutNonce(c *C) {
+	secret := crypt.New([]byte("secret"), 0)
+	pipe := pipeline.New()
+	secret.InstallSequenceStages(pipe, true)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: &component.Components{},
+			ClientAddr: "127.0.0.1:31560",
+			Pipeline:   pipe,
+		},
+		ServiceAddr: "127.0.0.1:31559",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	// the connection without nonce is refused, the sequenced messages could
+	// be replayed on it
+	var conn net.Conn
+	retry(c, func() (err error) {
+		conn, err = net.Dial("tcp", "127.0.0.1:31560")
+		return err
+	})
+	defer conn.Close()
+	p, err := codec.Encode(packet.Handshake, []byte(`{"sys":{}}`))
+	c.Assert(err, IsNil)
+	_, err = conn.Write(p)
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	c.Assert(err, IsNil)
+	packets, err := codec.NewDecoder().Decode(buf[:n])
+	c.Assert(err, IsNil)
+	c.Assert(packets, HasLen, 1)
+	res := struct {
+		Code int `json:"code"`
+	}{}
+	c.Assert(json.Unmarshal(packets[0].Data, &res), IsNil)
+	c.Assert(res.Code, Equals, 403)
+}
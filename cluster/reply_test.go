@@ -0,0 +1,97 @@
+package cluster_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	amoebaerrors "github.com/revzim/amoeba/errors"
+	"github.com/revzim/amoeba/session"
+)
+
+type (
+	QuoteComponent  struct{ component.Base }
+	LedgerComponent struct{ component.Base }
+)
+
+func (c *QuoteComponent) Price(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	if ping.Content == "" {
+		return nil, amoebaerrors.New(1002, "empty item")
+	}
+	return &testdata.Pong{Content: "price of " + ping.Content}, nil
+}
+
+func (c *QuoteComponent) Raw(s *session.Session, data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (c *LedgerComponent) Balance(ctx context.Context, s *session.Session, _ *testdata.Ping) (*testdata.Pong, error) {
+	if _, ok := component.FromContext(ctx); !ok {
+		return nil, errors.New("no request in context")
+	}
+	return &testdata.Pong{Content: "balance 100"}, nil
+}
+
+func (s *nodeSuite) TestReplyHandler(c *C) {
+	gateComps := &component.Components{}
+	gateComps.Register(&QuoteComponent{})
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: gateComps,
+			ClientAddr: "127.0.0.1:28551",
+		},
+		ServiceAddr: "127.0.0.1:28550",
+	}
+	c.Assert(gate.Startup(), IsNil)
+	defer gate.Shutdown()
+
+	ledgerComps := &component.Components{}
+	ledgerComps.Register(&LedgerComponent{})
+	ledger := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:28550",
+			Components:    ledgerComps,
+		},
+		ServiceAddr: "127.0.0.1:28552",
+	}
+	c.Assert(ledger.Startup(), IsNil)
+	defer ledger.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:28551")
+	defer c1.Close()
+
+	// the concurrent requests receive their own responses
+	chErr := make(chan error, 10)
+	for _, item := range []string{"sword", "shield", "bow", "axe", "helmet"} {
+		go func(item string) {
+			pong := &testdata.Pong{}
+			if err := c1.Call(context.Background(), "QuoteComponent.Price", &testdata.Ping{Content: item}, pong); err != nil {
+				chErr <- err
+				return
+			}
+			if pong.Content != "price of "+item {
+				chErr <- errors.New("unexpected response " + pong.Content)
+				return
+			}
+			chErr <- nil
+		}(item)
+	}
+	for i := 0; i < 5; i++ {
+		c.Assert(<-chErr, IsNil)
+	}
+
+	err := c1.Call(context.Background(), "QuoteComponent.Price", &testdata.Ping{}, &testdata.Pong{})
+	c.Assert(errors.Is(err, amoebaerrors.New(1002, "")), IsTrue, Commentf("%v", err))
+
+	var raw []byte
+	c.Assert(c1.Call(context.Background(), "QuoteComponent.Raw", []byte("raw"), &raw), IsNil)
+	c.Assert(string(raw), Equals, "raw")
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "LedgerComponent.Balance", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "balance 100")
+}
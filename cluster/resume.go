@@ -0,0 +1,234 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/session"
+)
+
+// The session of a broken connection is detached instead of closed if the
+// session resume enabled, the session data, group memberships and pushes are
+// kept for a grace period. The client could re-attach to the same session by
+// presenting the resume token received in handshake response.
+
+const (
+	resumeBacklog   = 128 // max pending messages of a detached session
+	resumeTokenSize = 16
+)
+
+type (
+	// parkedAgent is the network entity of detached session, which queues the
+	// messages sent to session until the session resumed or expired
+	parkedAgent struct {
+		*agent
+		resumer *resumer
+		token   string
+		timer   *time.Timer
+
+		mu      sync.Mutex
+		pending []pendingMessage
+		next    *agent // the agent which the session re-attached to
+	}
+
+	resumer struct {
+		sync.Mutex
+		handler *LocalHandler
+		grace   time.Duration
+		parked  map[string]*parkedAgent // resume token => detached agent
+	}
+)
+
+func newResumer(handler *LocalHandler, grace time.Duration) *resumer {
+	return &resumer{
+		handler: handler,
+		grace:   grace,
+		parked:  map[string]*parkedAgent{},
+	}
+}
+
+func (r *resumer) enabled() bool {
+	return r != nil && r.grace > 0
+}
+
+// token returns a new resume token, empty if session resume disabled
+func (r *resumer) token() string {
+	if !r.enabled() {
+		return ""
+	}
+	buf := make([]byte, resumeTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		log.Println("Generate resume token failed", err)
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// park detaches the session of agent and keeps it for a grace period
+func (r *resumer) park(a *agent) bool {
+	if !r.enabled() || a.token == "" || !a.isDetached() {
+		return false
+	}
+
+	p := &parkedAgent{agent: a, resumer: r, token: a.token}
+	r.Lock()
+	r.parked[p.token] = p
+	p.timer = time.AfterFunc(r.grace, func() { r.expire(p) })
+	r.Unlock()
+	a.session.Attach(p)
+
+	if env.Debug {
+		log.Printf("Session detached, ID=%d, UID=%d", a.session.ID(), a.session.UID())
+	}
+	return true
+}
+
+// resume takes the detached agent of token, nil if the token is unknown or
+// the session has expired
+func (r *resumer) resume(token string) *parkedAgent {
+	if !r.enabled() || token == "" {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	p, found := r.parked[token]
+	if !found {
+		return nil
+	}
+	// the timer has fired, the entry is left for expire to close the session
+	if !p.timer.Stop() {
+		return nil
+	}
+	delete(r.parked, token)
+	return p
+}
+
+// restore puts back the detached agent taken by resume whose handshake has
+// been refused, the grace period restarts from now
+func (r *resumer) restore(p *parkedAgent) {
+	r.Lock()
+	r.parked[p.token] = p
+	p.timer.Reset(r.grace)
+	r.Unlock()
+}
+
+// expire closes the detached session
+func (r *resumer) expire(p *parkedAgent) {
+	r.Lock()
+	if r.parked[p.token] != p {
+		r.Unlock()
+		return
+	}
+	delete(r.parked, p.token)
+	p.timer.Stop()
+	r.Unlock()
+
+	if env.Debug {
+		log.Printf("Session expired, ID=%d, UID=%d", p.session.ID(), p.session.UID())
+	}
+	r.handler.closeSession(p.agent)
+}
+
+// queue keeps the message until the session resumed, the message will be sent
+// to the new agent directly if the session has been re-attached
+func (p *parkedAgent) queue(m pendingMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next != nil {
+		return p.next.deliver(m)
+	}
+	if len(p.pending) >= resumeBacklog {
+		return ErrBufferExceed
+	}
+	p.pending = append(p.pending, m)
+	return nil
+}
+
+// attach sends the pending messages to agent, and the later messages will be
+// sent to agent directly
+func (p *parkedAgent) attach(a *agent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next = a
+	for _, m := range p.pending {
+		if err := a.send(m); err != nil {
+			return err
+		}
+	}
+	p.pending = nil
+	return nil
+}
+
+// Push, implementation for session.NetworkEntity interface
+func (p *parkedAgent) Push(route string, v interface{}) error {
+	return p.queue(pendingMessage{typ: message.Push, route: route, payload: v})
+}
+
+// Response, implementation for session.NetworkEntity interface
+func (p *parkedAgent) Response(v interface{}) error {
+	return p.ResponseMid(p.lastMid, v)
+}
+
+// ResponseMid, implementation for session.NetworkEntity interface
+func (p *parkedAgent) ResponseMid(mid uint64, v interface{}) error {
+	if mid <= 0 {
+		return ErrSessionOnNotify
+	}
+	return p.queue(pendingMessage{typ: message.Response, mid: mid, payload: v, serializer: p.serializers.take(mid)})
+}
+
+// responseError queues the error envelope as the response of request
+func (p *parkedAgent) responseError(mid uint64, data []byte) error {
+	p.serializers.take(mid)
+	return p.queue(pendingMessage{typ: message.Response, mid: mid, payload: data, isError: true})
+}
+
+// Kick, implementation for session.NetworkEntity interface, the detached
+// session will be closed immediately because there is no connection
+func (p *parkedAgent) Kick(_ string) error {
+	return p.Close()
+}
+
+// Close, implementation for session.NetworkEntity interface, the detached
+// session will be closed immediately
+func (p *parkedAgent) Close() error {
+	p.resumer.expire(p)
+	return nil
+}
+
+// gateAgent returns the agent of session connected to current node, includes
+// the detached one
+func gateAgent(s *session.Session) (*agent, bool) {
+	switch a := s.NetworkEntity().(type) {
+	case *agent:
+		return a, true
+	case *parkedAgent:
+		return a.agent, true
+	}
+	return nil, false
+}
@@ -0,0 +1,340 @@
+package cluster_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/internal/codec"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/packet"
+	"github.com/revzim/amoeba/session"
+)
+
+type LoungeComponent struct {
+	component.Base
+	sync.Mutex
+	sessions map[int64]*session.Session
+}
+
+func (c *LoungeComponent) Join(s *session.Session, ping *testdata.Ping) error {
+	c.Lock()
+	c.sessions[s.ID()] = s
+	c.Unlock()
+	s.Set("room", ping.Content)
+	return s.Response(&testdata.Pong{Content: fmt.Sprintf("%d:%s", s.ID(), s.String("room"))})
+}
+
+func (c *LoungeComponent) Whereami(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: fmt.Sprintf("%d:%s", s.ID(), s.String("room"))})
+}
+
+func (c *LoungeComponent) session(sid int64) *session.Session {
+	c.Lock()
+	defer c.Unlock()
+	return c.sessions[sid]
+}
+
+func (s *nodeSuite) TestSessionResume(c *C) {
+	chClosed := make(chan int64, 4)
+	session.Lifetime.OnClosed(func(s *session.Session) {
+		if s.String("room") == "lounge" {
+			chClosed <- s.ID()
+		}
+	})
+
+	lounge := &LoungeComponent{sessions: map[int64]*session.Session{}}
+	comps := &component.Components{}
+	comps.Register(lounge)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:21551",
+			ResumeGrace: 500 * time.Millisecond,
+		},
+		ServiceAddr: "127.0.0.1:21550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:21551")
+	c.Assert(c1.ResumeToken(), Not(Equals), "")
+	c.Assert(c1.Resumed(), IsFalse)
+
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "LoungeComponent.Join", &testdata.Ping{Content: "lounge"}, pong), IsNil)
+	var sid int64
+	fmt.Sscanf(pong.Content, "%d:", &sid)
+	c.Assert(sid > 0, IsTrue)
+
+	// the pushes to detached session are kept until resumed
+	c1.Close()
+	for i := 0; i < 50 && node.DetachedSessions() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(node.DetachedSessions(), Equals, 1)
+	c.Assert(lounge.session(sid).Push("pushed", &testdata.Pong{Content: "pending"}), IsNil)
+
+	chPush := make(chan string, 1)
+	c2, err := client.Dial("127.0.0.1:21551",
+		client.WithResumeToken(c1.ResumeToken()),
+		client.WithPushHandler("pushed", func(pong *testdata.Pong) { chPush <- pong.Content }))
+	c.Assert(err, IsNil)
+	c.Assert(c2.Resumed(), IsTrue)
+	c.Assert(c2.ResumeToken(), Not(Equals), c1.ResumeToken())
+	select {
+	case content := <-chPush:
+		c.Assert(content, Equals, "pending")
+	case <-time.After(3 * time.Second):
+		c.Fatal("pending push not received")
+	}
+
+	c.Assert(c2.Call(context.Background(), "LoungeComponent.Whereami", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, fmt.Sprintf("%d:lounge", sid))
+	select {
+	case id := <-chClosed:
+		c.Fatalf("session %d closed before expired", id)
+	default:
+	}
+
+	// the token can only be used once
+	c3, err := client.Dial("127.0.0.1:21551", client.WithResumeToken(c1.ResumeToken()))
+	c.Assert(err, IsNil)
+	c.Assert(c3.Resumed(), IsFalse)
+	c3.Close()
+
+	// the session will be closed after the grace period
+	c2.Close()
+	select {
+	case id := <-chClosed:
+		c.Assert(id, Equals, sid)
+	case <-time.After(3 * time.Second):
+		c.Fatal("detached session not expired")
+	}
+}
+
+func (s *nodeSuite) TestSessionResumeRefused(c *C) {
+	lounge := &LoungeComponent{sessions: map[int64]*session.Session{}}
+	comps := &component.Components{}
+	comps.Register(lounge)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:21553",
+			ResumeGrace: 2 * time.Second,
+			HandshakeHandler: func(s *session.Session) (interface{}, error) {
+				if s.HandshakeInfo().Version == "banned" {
+					return nil, errors.New("banned version")
+				}
+				return nil, nil
+			},
+		},
+		ServiceAddr: "127.0.0.1:21552",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:21553")
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "LoungeComponent.Join", &testdata.Ping{Content: "lounge"}, pong), IsNil)
+	var sid int64
+	fmt.Sscanf(pong.Content, "%d:", &sid)
+
+	c1.Close()
+	for i := 0; i < 50 && node.DetachedSessions() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(lounge.session(sid).Push("pushed", &testdata.Pong{Content: "pending"}), IsNil)
+
+	// the refused handshake leaves the detached session with its old token
+	_, err := client.Dial("127.0.0.1:21553",
+		client.WithResumeToken(c1.ResumeToken()),
+		client.WithClientVersion("", "banned"))
+	c.Assert(err, Equals, client.ErrRejected)
+	for i := 0; i < 50 && node.DetachedSessions() != 1; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(node.DetachedSessions(), Equals, 1)
+
+	chPush := make(chan string, 1)
+	c2, err := client.Dial("127.0.0.1:21553",
+		client.WithResumeToken(c1.ResumeToken()),
+		client.WithPushHandler("pushed", func(pong *testdata.Pong) { chPush <- pong.Content }))
+	c.Assert(err, IsNil)
+	defer c2.Close()
+	c.Assert(c2.Resumed(), IsTrue)
+	select {
+	case content := <-chPush:
+		c.Assert(content, Equals, "pending")
+	case <-time.After(3 * time.Second):
+		c.Fatal("pending push not received")
+	}
+	c.Assert(c2.Call(context.Background(), "LoungeComponent.Whereami", &testdata.Ping{}, pong), IsNil)
+	c.Assert(pong.Content, Equals, fmt.Sprintf("%d:lounge", sid))
+}
+
+// silentProxy forwards the connections to addr until frozen, after which the
+// bytes are dropped without closing the connections, like a link died silently
+type silentProxy struct {
+	sync.Mutex
+	listener net.Listener
+	addr     string
+	frozen   int32
+	conns    []net.Conn
+}
+
+func (p *silentProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", p.addr)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		p.Lock()
+		p.conns = append(p.conns, conn, upstream)
+		p.Unlock()
+		go p.pipe(conn, upstream)
+		go p.pipe(upstream, conn)
+	}
+}
+
+func (p *silentProxy) pipe(dst, src net.Conn) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			return
+		}
+		if atomic.LoadInt32(&p.frozen) == 0 {
+			dst.Write(buf[:n])
+		}
+	}
+}
+
+func (p *silentProxy) close() {
+	p.listener.Close()
+	p.Lock()
+	defer p.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
+
+func (s *nodeSuite) TestResumeAfterHeartbeatTimeout(c *C) {
+	heartbeat := env.Heartbeat
+	env.Heartbeat = 100 * time.Millisecond
+	defer func() { env.Heartbeat = heartbeat }()
+
+	lounge := &LoungeComponent{sessions: map[int64]*session.Session{}}
+	comps := &component.Components{}
+	comps.Register(lounge)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:21561",
+			ResumeGrace: 2 * time.Second,
+		},
+		ServiceAddr: "127.0.0.1:21560",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:21562")
+	c.Assert(err, IsNil)
+	proxy := &silentProxy{listener: listener, addr: "127.0.0.1:21561"}
+	go proxy.serve()
+	defer proxy.close()
+
+	c1 := dial(c, "127.0.0.1:21562")
+	defer c1.Close()
+	pong := &testdata.Pong{}
+	c.Assert(c1.Call(context.Background(), "LoungeComponent.Join", &testdata.Ping{Content: "cellar"}, pong), IsNil)
+
+	// the session is detached rather than closed once the heartbeat timed out
+	atomic.StoreInt32(&proxy.frozen, 1)
+	for i := 0; i < 100 && node.DetachedSessions() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(node.DetachedSessions(), Equals, 1)
+
+	c2, err := client.Dial("127.0.0.1:21561", client.WithResumeToken(c1.ResumeToken()))
+	c.Assert(err, IsNil)
+	defer c2.Close()
+	c.Assert(c2.Resumed(), IsTrue)
+	resumed := &testdata.Pong{}
+	c.Assert(c2.Call(context.Background(), "LoungeComponent.Whereami", &testdata.Ping{}, resumed), IsNil)
+	c.Assert(resumed.Content, Equals, pong.Content)
+}
+
+func (s *nodeSuite) TestHandshakeOnce(c *C) {
+	lounge := &LoungeComponent{sessions: map[int64]*session.Session{}}
+	comps := &component.Components{}
+	comps.Register(lounge)
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:    true,
+			Components:  comps,
+			ClientAddr:  "127.0.0.1:21564",
+			ResumeGrace: 2 * time.Second,
+		},
+		ServiceAddr: "127.0.0.1:21563",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	c1 := dial(c, "127.0.0.1:21564")
+	c1.Close()
+	for i := 0; i < 50 && node.DetachedSessions() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.Assert(node.DetachedSessions(), Equals, 1)
+
+	conn, err := net.Dial("tcp", "127.0.0.1:21564")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	handshake := func(data map[string]interface{}) {
+		hs, err := json.Marshal(map[string]interface{}{"sys": data})
+		c.Assert(err, IsNil)
+		p, err := codec.Encode(packet.Handshake, hs)
+		c.Assert(err, IsNil)
+		_, err = conn.Write(p)
+		c.Assert(err, IsNil)
+	}
+	handshake(map[string]interface{}{})
+	_, err = conn.Read(make([]byte, 4096))
+	c.Assert(err, IsNil)
+	ack, err := codec.Encode(packet.HandshakeAck, nil)
+	c.Assert(err, IsNil)
+	_, err = conn.Write(ack)
+	c.Assert(err, IsNil)
+
+	// the working connection can not take over the detached session
+	handshake(map[string]interface{}{"resume": c1.ResumeToken()})
+	c.Assert(conn.SetReadDeadline(time.Now().Add(3*time.Second)), IsNil)
+	_, err = ioutil.ReadAll(conn)
+	c.Assert(err, IsNil)
+
+	// the detached session is still kept for its own client
+	c2, err := client.Dial("127.0.0.1:21564", client.WithResumeToken(c1.ResumeToken()))
+	c.Assert(err, IsNil)
+	defer c2.Close()
+	c.Assert(c2.Resumed(), IsTrue)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/serialize"
+	"github.com/revzim/amoeba/session"
+)
+
+// pendingSerializers records the serializers of the requests which handled by
+// the handlers with custom serializer, the responses of the requests will be
+// serialized by the same serializer
+type pendingSerializers struct {
+	mu sync.Mutex
+	m  map[uint64]serialize.Serializer
+}
+
+func (p *pendingSerializers) set(mid uint64, s serialize.Serializer) {
+	p.mu.Lock()
+	if p.m == nil {
+		p.m = map[uint64]serialize.Serializer{}
+	}
+	p.m[mid] = s
+	p.mu.Unlock()
+}
+
+// take returns the serializer of request and forgets it, nil will be returned
+// if the request is handled with the default serializer
+func (p *pendingSerializers) take(mid uint64) serialize.Serializer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, found := p.m[mid]
+	if found {
+		delete(p.m, mid)
+	}
+	return s
+}
+
+// moveTo moves all pending serializers to other
+func (p *pendingSerializers) moveTo(other *pendingSerializers) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for mid, s := range p.m {
+		other.set(mid, s)
+	}
+	p.m = nil
+}
+
+// serializersOf returns the pending serializers of the network entity of
+// session, nil will be returned if the entity has no pending serializers
+func serializersOf(s *session.Session) *pendingSerializers {
+	switch v := s.NetworkEntity().(type) {
+	case *agent:
+		return &v.serializers
+	case *parkedAgent:
+		return &v.serializers
+	case *acceptor:
+		return &v.serializers
+	}
+	return nil
+}
+
+// routeSerializer returns the serializer of route resolved by serializerOf, nil
+// will be returned if serializerOf is nil
+func routeSerializer(serializerOf func(route string) serialize.Serializer, route string) serialize.Serializer {
+	if serializerOf == nil {
+		return nil
+	}
+	return serializerOf(route)
+}
+
+// serializerOf returns the serializer of local handler or service of the route,
+// or the serializer of remote service announced by the member which provides
+// it. nil will be returned if the route uses the default serializer
+func (h *LocalHandler) serializerOf(route string) serialize.Serializer {
+	if handler, found := h.localHandlers[route]; found {
+		return handler.Serializer
+	}
+	index := strings.LastIndex(route, ".")
+	if index <= 0 {
+		return nil
+	}
+	service := route[:index]
+	if s, found := h.localServices[service]; found {
+		return s.Serializer
+	}
+
+	h.RLock()
+	members := h.remoteServices[service]
+	h.RUnlock()
+	if len(members) == 0 {
+		return nil
+	}
+	name := members[0].Serializers[service]
+	if name == "" {
+		return nil
+	}
+	serializer := serialize.Lookup(name)
+	if serializer == nil {
+		log.Printf("Serializer %s of service %s is not registered, use default", name, service)
+	}
+	return serializer
+}
+
+// LocalSerializers returns the serializer names of local services which
+// specified by component.WithSerializer, the serializers not registered by
+// serialize.Register are omitted
+func (h *LocalHandler) LocalSerializers() map[string]string {
+	var result map[string]string
+	for name, service := range h.localServices {
+		serializer := serialize.NameOf(service.Serializer)
+		if serializer == "" {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[name] = serializer
+	}
+	return result
+}
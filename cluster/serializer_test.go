@@ -0,0 +1,103 @@
+package cluster_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/client"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/serialize/msgpack"
+	"github.com/revzim/amoeba/session"
+)
+
+type Profile struct {
+	Name  string `json:"name" msgpack:"name"`
+	Level int    `json:"level" msgpack:"level"`
+}
+
+type ProfileComponent struct{ component.Base }
+
+func (c *ProfileComponent) Get(s *session.Session, p *Profile) error {
+	if err := s.Push("ProfileComponent.Changed", &Profile{Name: p.Name, Level: p.Level + 1}); err != nil {
+		return err
+	}
+	return s.Response(&Profile{Name: p.Name, Level: p.Level})
+}
+
+// Ignore returns without responding
+func (c *ProfileComponent) Ignore(s *session.Session, p *Profile) error {
+	return nil
+}
+
+func (s *nodeSuite) TestComponentSerializer(c *C) {
+	comps := &component.Components{}
+	comps.Register(&ProfileComponent{}, component.WithSerializer(msgpack.NewSerializer()))
+	comps.Register(&ChatComponent{})
+	node := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:   true,
+			Components: comps,
+			ClientAddr: "127.0.0.1:25551",
+		},
+		ServiceAddr: "127.0.0.1:25550",
+	}
+	c.Assert(node.Startup(), IsNil)
+	defer node.Shutdown()
+
+	chChanged := make(chan *Profile, 1)
+	cm := dial(c, "127.0.0.1:25551",
+		client.WithSerializer(msgpack.NewSerializer()),
+		client.WithPushHandler("ProfileComponent.Changed", func(p *Profile) { chChanged <- p }))
+	defer cm.Close()
+	profile := &Profile{}
+	c.Assert(cm.Call(context.Background(), "ProfileComponent.Get", &Profile{Name: "amoeba", Level: 1}, profile), IsNil)
+	c.Assert(*profile, Equals, Profile{Name: "amoeba", Level: 1})
+	c.Assert(*<-chChanged, Equals, Profile{Name: "amoeba", Level: 2})
+
+	// the serializer of request is forgotten if the handler never responds
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	c.Assert(cm.Call(ctx, "ProfileComponent.Ignore", &Profile{Name: "amoeba"}, profile), Equals, context.DeadlineExceeded)
+	c.Assert(node.PendingSerializers(), Equals, 0)
+
+	// the other services still use the default serializer
+	cp, err := client.Dial("127.0.0.1:25551")
+	c.Assert(err, IsNil)
+	defer cp.Close()
+	pong := &testdata.Pong{}
+	c.Assert(cp.Call(context.Background(), "ChatComponent.Echo", &testdata.Ping{Content: "ping"}, pong), IsNil)
+	c.Assert(pong.Content, Equals, "echo ping")
+}
+
+func (c *ProfileComponent) Promote(s *session.Session, p *Profile) error {
+	return s.Response(&Profile{Name: p.Name, Level: p.Level + 1})
+}
+
+func (s *nodeSuite) TestRemoteCallSerializer(c *C) {
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: &component.Components{}},
+		ServiceAddr: "127.0.0.1:25552",
+	}
+	c.Assert(master.Startup(), IsNil)
+	defer master.Shutdown()
+
+	comps := &component.Components{}
+	comps.Register(&ProfileComponent{}, component.WithSerializer(msgpack.NewSerializer()))
+	profiles := &cluster.Node{
+		Options:     cluster.Options{AdvertiseAddr: "127.0.0.1:25552", Components: comps},
+		ServiceAddr: "127.0.0.1:25553",
+	}
+	c.Assert(profiles.Startup(), IsNil)
+	defer profiles.Shutdown()
+
+	// the argument and reply are serialized by the serializer announced by
+	// the member providing the service
+	profile := &Profile{}
+	retry(c, func() error {
+		return master.Call(context.Background(), "ProfileComponent.Promote", &Profile{Name: "amoeba", Level: 1}, profile)
+	})
+	c.Assert(*profile, Equals, Profile{Name: "amoeba", Level: 2})
+}
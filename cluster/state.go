@@ -0,0 +1,191 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"sync"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/session"
+)
+
+// The session state is shared between the gate and backend nodes, the gate is
+// the source of truth: backend fetches the state from gate when the session is
+// created in backend firstly, and the changes in any node will be synchronized
+// to the gate, which forwards them to the other backends holding the session.
+//
+// Only the changed keys are synchronized and merged by the receivers, so the
+// changes of different keys from different nodes will not overwrite each other.
+// The values are encoded with encoding/gob one by one, the custom types stored
+// in session must be registered by gob.Register, the values can not be encoded
+// (e.g. the scheduler of service) are kept in local node only.
+
+type (
+	// stateDelta is the wire format of session.Delta
+	stateDelta struct {
+		Values     map[string][]byte
+		Removed    []string
+		Reset      bool
+		UIDChanged bool
+	}
+
+	// stateQueue sends the deltas of a session to a node in order without
+	// blocking the caller, the deltas queued during sending are merged
+	stateQueue struct {
+		sync.Mutex
+		uid     int64
+		pending *session.Delta
+		running bool
+		send    func(uid int64, delta session.Delta)
+	}
+)
+
+func encodeState(delta session.Delta) ([]byte, error) {
+	wire := stateDelta{
+		Values:     make(map[string][]byte, len(delta.Values)),
+		Removed:    delta.Removed,
+		Reset:      delta.Reset,
+		UIDChanged: delta.UIDChanged,
+	}
+	for k, v := range delta.Values {
+		data, err := encodeValue(v)
+		if err != nil {
+			log.Println("Skip session state which can not be encoded", k, err)
+			continue
+		}
+		wire.Values[k] = data
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(wire); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeValue(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeState(data []byte) (session.Delta, error) {
+	delta := session.Delta{Values: map[string]interface{}{}}
+	if len(data) == 0 {
+		return delta, nil
+	}
+	wire := stateDelta{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&wire); err != nil {
+		return delta, err
+	}
+	for k, raw := range wire.Values {
+		var v interface{}
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&v); err != nil {
+			log.Println("Skip session state which can not be decoded", k, err)
+			continue
+		}
+		delta.Values[k] = v
+	}
+	delta.Removed = wire.Removed
+	delta.Reset = wire.Reset
+	delta.UIDChanged = wire.UIDChanged
+	return delta, nil
+}
+
+// applyState merges the delta synchronized from other node into session, the
+// local values which can not be encoded are never synchronized, so they are
+// kept even if the delta resets the whole state
+func applyState(s *session.Session, uid int64, delta session.Delta) {
+	if delta.Reset {
+		values := make(map[string]interface{}, len(delta.Values))
+		for k, v := range s.State() {
+			if _, err := encodeValue(v); err != nil {
+				values[k] = v
+			}
+		}
+		for k, v := range delta.Values {
+			values[k] = v
+		}
+		delta.Values = values
+	}
+	s.Apply(uid, delta)
+}
+
+func newStateQueue(send func(uid int64, delta session.Delta)) *stateQueue {
+	return &stateQueue{send: send}
+}
+
+// push queues the delta, the queued deltas are sent by a goroutine which exits
+// once the queue drained
+func (q *stateQueue) push(uid int64, delta session.Delta) {
+	q.Lock()
+	// the uid is sent only by the delta changed it, see session.Delta
+	if delta.UIDChanged {
+		q.uid = uid
+	}
+	if q.pending == nil {
+		q.pending = &session.Delta{}
+	}
+	q.pending.Merge(delta)
+	if q.running {
+		q.Unlock()
+		return
+	}
+	q.running = true
+	q.Unlock()
+	go q.run()
+}
+
+func (q *stateQueue) run() {
+	for {
+		q.Lock()
+		if q.pending == nil {
+			q.running = false
+			q.Unlock()
+			return
+		}
+		uid, delta := q.uid, *q.pending
+		q.pending = nil
+		q.Unlock()
+		q.send(uid, delta)
+	}
+}
+
+// syncState sends the session state to the node of addr, failures are logged
+// only because the state will be fetched again if the node recreates session
+func (h *LocalHandler) syncState(addr string, req *clusterpb.SyncSessionRequest) {
+	pool, err := h.currentNode.rpcClient.getConnPool(addr)
+	if err != nil {
+		log.Println("Sync session state failed", addr, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), env.CallTimeout)
+	defer cancel()
+	if _, err = clusterpb.NewMemberClient(pool.Get()).SyncSession(ctx, req); err != nil {
+		log.Println("Sync session state failed", addr, err)
+	}
+}
@@ -0,0 +1,173 @@
+package cluster_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/io"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	LobbyComponent struct{ component.Base }
+	RoomComponent  struct{ component.Base }
+	TitleComponent struct{ component.Base }
+)
+
+func whoami(s *session.Session) *testdata.Pong {
+	return &testdata.Pong{Content: fmt.Sprintf("%d:%s:%s", s.UID(), s.String("nickname"), s.String("title"))}
+}
+
+func (c *LobbyComponent) Login(s *session.Session, ping *testdata.Ping) error {
+	if err := s.Bind(10086); err != nil {
+		return err
+	}
+	// the values can not be encoded are kept in current node only
+	s.Set("conn", make(chan struct{}))
+	s.Set("nickname", ping.Content)
+	return s.Response(whoami(s))
+}
+
+// Entitle sets the key held by gate only
+func (c *TitleComponent) Entitle(s *session.Session, ping *testdata.Ping) error {
+	s.Set("title", ping.Content)
+	return s.Response(whoami(s))
+}
+
+// Sid responds the session id held by gate
+func (c *TitleComponent) Sid(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: strconv.FormatInt(s.ID(), 10)})
+}
+
+func (c *TitleComponent) Whoami(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(whoami(s))
+}
+
+func (c *LobbyComponent) Whoami(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(whoami(s))
+}
+
+func (c *RoomComponent) Rename(s *session.Session, ping *testdata.Ping) error {
+	s.Set("nickname", ping.Content)
+	return s.Response(whoami(s))
+}
+
+// Conn responds whether the value can not be encoded is kept in lobby
+func (c *LobbyComponent) Conn(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: strconv.FormatBool(s.HasKey("conn"))})
+}
+
+func (c *RoomComponent) Clear(s *session.Session, _ *testdata.Ping) error {
+	s.Clear()
+	return s.Response(whoami(s))
+}
+
+func (c *RoomComponent) Whoami(s *session.Session, _ *testdata.Ping) error {
+	return s.Response(whoami(s))
+}
+
+func (s *nodeSuite) TestSessionStateSync(c *C) {
+	masterComps := &component.Components{}
+	masterComps.Register(&MasterComponent{})
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: masterComps},
+		ServiceAddr: "127.0.0.1:16550",
+	}
+	c.Assert(master.Startup(), IsNil)
+
+	gateComps := &component.Components{}
+	gateComps.Register(&GateComponent{})
+	gateComps.Register(&TitleComponent{})
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr: "127.0.0.1:16550",
+			ClientAddr:    "127.0.0.1:16552",
+			Components:    gateComps,
+		},
+		ServiceAddr: "127.0.0.1:16551",
+	}
+	c.Assert(gate.Startup(), IsNil)
+
+	lobbyComps := &component.Components{}
+	lobbyComps.Register(&LobbyComponent{})
+	lobby := &cluster.Node{
+		Options:     cluster.Options{AdvertiseAddr: "127.0.0.1:16550", Components: lobbyComps},
+		ServiceAddr: "127.0.0.1:16553",
+	}
+	c.Assert(lobby.Startup(), IsNil)
+
+	roomComps := &component.Components{}
+	roomComps.Register(&RoomComponent{})
+	room := &cluster.Node{
+		Options:     cluster.Options{AdvertiseAddr: "127.0.0.1:16550", Components: roomComps},
+		ServiceAddr: "127.0.0.1:16554",
+	}
+	c.Assert(room.Startup(), IsNil)
+
+	connector := io.NewConnector()
+	chWait := make(chan struct{})
+	connector.OnConnected(func() {
+		chWait <- struct{}{}
+	})
+	c.Assert(connector.Start("127.0.0.1:16552"), IsNil)
+	<-chWait
+
+	onResult := make(chan string)
+	request := func(route, content string) string {
+		err := connector.Request(route, &testdata.Ping{Content: content}, func(data interface{}) {
+			onResult <- string(data.([]byte))
+		})
+		c.Assert(err, IsNil)
+		return <-onResult
+	}
+
+	// the changes are synchronized asynchronously
+	eventually := func(route, expected string) {
+		for i := 0; i < 50; i++ {
+			if strings.Contains(request(route, ""), expected) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		c.Fatalf("%s: state %s not synchronized", route, expected)
+	}
+
+	// The state changed in backend is visible to other backends
+	c.Assert(strings.Contains(request("TitleComponent.Entitle", "knight"), "0::knight"), IsTrue)
+	c.Assert(strings.Contains(request("LobbyComponent.Login", "amoeba"), "10086:amoeba:knight"), IsTrue)
+	eventually("RoomComponent.Whoami", "10086:amoeba:knight")
+
+	// The changes are forwarded to the backends which hold the session, and
+	// merged with the keys held by gate
+	c.Assert(strings.Contains(request("RoomComponent.Rename", "revzim"), "10086:revzim:knight"), IsTrue)
+	eventually("LobbyComponent.Whoami", "10086:revzim:knight")
+	eventually("TitleComponent.Whoami", "10086:revzim:knight")
+
+	// The stale uid sent with the changes of backend does not overwrite the
+	// uid bound to gate
+	pong := &testdata.Pong{}
+	c.Assert(proto.Unmarshal([]byte(request("TitleComponent.Sid", "")), pong), IsNil)
+	sid, err := strconv.ParseInt(pong.Content, 10, 64)
+	c.Assert(err, IsNil)
+	c.Assert(gate.SyncStale("127.0.0.1:16554", sid, 0, map[string]interface{}{"nickname": "stale"}), IsNil)
+	c.Assert(strings.Contains(request("TitleComponent.Whoami", ""), "10086:stale:knight"), IsTrue)
+
+	// The cleared state is synchronized to all nodes, the values can not be
+	// encoded are kept in the node set them
+	c.Assert(strings.Contains(request("RoomComponent.Clear", ""), "0::"), IsTrue)
+	eventually("TitleComponent.Whoami", "0::")
+	eventually("LobbyComponent.Whoami", "0::")
+	c.Assert(strings.Contains(request("LobbyComponent.Conn", ""), "true"), IsTrue)
+
+	room.Shutdown()
+	lobby.Shutdown()
+	gate.Shutdown()
+	master.Shutdown()
+}
@@ -0,0 +1,400 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/internal/message"
+	"google.golang.org/grpc/metadata"
+)
+
+// The members can exchange the forwarded messages(request, notify, push,
+// multicast, response, kick and close) over one long-lived bidirectional stream
+// instead of unary calls. The messages received from a stream are dispatched
+// one by one, so the order of messages of a session is guaranteed, the messages
+// of the session being created are queued without blocking the messages of
+// other sessions. The sender will be blocked if the send backlog is full, which
+// applies backpressure to the producer. The messages to clients are queued per
+// session and never block the stream, the connection of slow client is closed
+// once its delivery queue is full.
+
+const (
+	streamSendBacklog = 256
+	streamAddrKey     = "amoeba-service-addr"   // metadata key of the peer address
+	streamTimeoutKey  = "amoeba-stream-timeout" // metadata key of the forwarded message timeout
+)
+
+type (
+	// messageStream is implemented by both client and server side streams
+	messageStream interface {
+		Send(*clusterpb.StreamMessage) error
+		Recv() (*clusterpb.StreamMessage, error)
+	}
+
+	memberStream struct {
+		addr   string // service address of peer
+		stream messageStream
+		chSend chan *clusterpb.StreamMessage
+		chDie  chan struct{}
+		once   sync.Once
+		cancel context.CancelFunc // cancels the client side stream
+	}
+
+	// streamer manages the streams between current node and other members
+	streamer struct {
+		sync.Mutex
+		node    *Node
+		streams map[string]*memberStream
+		chDie   chan struct{}
+	}
+)
+
+func newMemberStream(addr string, stream messageStream, cancel context.CancelFunc) *memberStream {
+	return &memberStream{
+		addr:   addr,
+		stream: stream,
+		chSend: make(chan *clusterpb.StreamMessage, streamSendBacklog),
+		chDie:  make(chan struct{}),
+		cancel: cancel,
+	}
+}
+
+// send queues the message, it blocks if the backlog is full until the message
+// is queued or the call timeout exceeded
+func (s *memberStream) send(msg *clusterpb.StreamMessage) error {
+	select {
+	case s.chSend <- msg:
+		return nil
+	case <-s.chDie:
+		return ErrStreamClosed
+	default:
+	}
+
+	timer := time.NewTimer(env.CallTimeout)
+	defer timer.Stop()
+	select {
+	case s.chSend <- msg:
+		return nil
+	case <-s.chDie:
+		return ErrStreamClosed
+	case <-timer.C:
+		return ErrStreamBackpressure
+	}
+}
+
+func (s *memberStream) write() {
+	for {
+		select {
+		case msg := <-s.chSend:
+			if err := s.stream.Send(msg); err != nil {
+				log.Println("Send stream message failed", s.addr, err)
+				s.close()
+				return
+			}
+		case <-s.chDie:
+			return
+		}
+	}
+}
+
+func (s *memberStream) close() {
+	s.once.Do(func() {
+		close(s.chDie)
+		if s.cancel != nil {
+			s.cancel()
+		}
+	})
+}
+
+func newStreamer(node *Node) *streamer {
+	return &streamer{
+		node:    node,
+		streams: map[string]*memberStream{},
+		chDie:   make(chan struct{}),
+	}
+}
+
+// send sends the message to the member over stream, a new stream will be
+// opened if there is no stream to the member
+func (s *streamer) send(addr string, msg *clusterpb.StreamMessage) error {
+	st, err := s.open(addr)
+	if err != nil {
+		return err
+	}
+	return st.send(msg)
+}
+
+func (s *streamer) open(addr string) (*memberStream, error) {
+	s.Lock()
+	st, found := s.streams[addr]
+	s.Unlock()
+	if found {
+		return st, nil
+	}
+
+	// dial without lock, the streams to other members are not blocked
+	pool, err := s.node.rpcClient.getConnPool(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = metadata.AppendToOutgoingContext(ctx, streamAddrKey, s.node.ServiceAddr)
+	cs, err := clusterpb.NewMemberClient(pool.Get()).Stream(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	select {
+	case <-s.chDie:
+		cancel()
+		return nil, ErrStreamClosed
+	default:
+	}
+	// the stream opened concurrently is used
+	if st, found := s.streams[addr]; found {
+		cancel()
+		return st, nil
+	}
+	st = newMemberStream(addr, cs, cancel)
+	s.streams[addr] = st
+	go st.write()
+	go s.read(st)
+	return st, nil
+}
+
+// serve registers the stream accepted from other member, the stream will be
+// used to send messages to the member if there is no existing stream
+func (s *streamer) serve(addr string, ss clusterpb.Member_StreamServer) {
+	st := newMemberStream(addr, ss, nil)
+	s.Lock()
+	if _, found := s.streams[addr]; !found {
+		s.streams[addr] = st
+	}
+	s.Unlock()
+
+	// The server side stream must not be used to send after the handler
+	// returned, the pending Recv will be aborted once the handler returned
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		st.write()
+	}()
+	go s.read(st)
+
+	select {
+	case <-st.chDie:
+	case <-s.chDie:
+		st.close()
+	}
+	wg.Wait()
+}
+
+// read dispatches the received messages in order until the stream closed
+func (s *streamer) read(st *memberStream) {
+	defer func() {
+		st.close()
+		s.Lock()
+		if s.streams[st.addr] == st {
+			delete(s.streams, st.addr)
+		}
+		s.Unlock()
+	}()
+
+	for {
+		msg, err := st.stream.Recv()
+		if err != nil {
+			return
+		}
+		s.node.dispatch(msg)
+	}
+}
+
+func (s *streamer) close() {
+	s.Lock()
+	defer s.Unlock()
+	select {
+	case <-s.chDie:
+	default:
+		close(s.chDie)
+	}
+	for addr, st := range s.streams {
+		st.close()
+		delete(s.streams, addr)
+	}
+}
+
+// setStreamTimeout records the timeout in the metadata of forwarded message,
+// the stream has no per-message deadline like the unary call. The remaining
+// duration rather than the absolute deadline is sent like grpc-timeout, so the
+// clock skew between nodes does not matter
+func setStreamTimeout(md map[string]string, timeout time.Duration) {
+	md[streamTimeoutKey] = strconv.FormatInt(int64(timeout), 10)
+}
+
+// streamContext returns the context with the deadline rebuilt from the timeout
+// recorded in metadata by the clock of current node, the timeout is removed
+// from metadata before passed to handlers
+func streamContext(md map[string]string) (context.Context, context.CancelFunc) {
+	v, found := md[streamTimeoutKey]
+	if !found {
+		return context.Background(), func() {}
+	}
+	delete(md, streamTimeoutKey)
+	ns, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeout(context.Background(), time.Duration(ns))
+}
+
+// dispatch handles the message received from stream, the requests and
+// notifies of the session unknown to current node are queued until the
+// session is created, so fetching its state never blocks the stream
+func (n *Node) dispatch(msg *clusterpb.StreamMessage) {
+	switch m := msg.Message.(type) {
+	case *clusterpb.StreamMessage_Request:
+		if n.backlog(m.Request.SessionId, m.Request.GateAddr, msg) {
+			return
+		}
+	case *clusterpb.StreamMessage_Notify:
+		if n.backlog(m.Notify.SessionId, m.Notify.GateAddr, msg) {
+			return
+		}
+	}
+	n.handleStreamMessage(msg)
+}
+
+// backlog queues the message if the session is not created yet or still has
+// queued messages, it reports whether the message is queued. The session is
+// created in background and its queued messages are handled in order
+func (n *Node) backlog(sid int64, gateAddr string, msg *clusterpb.StreamMessage) bool {
+	n.Lock()
+	defer n.Unlock()
+	if pending, found := n.backlogs[sid]; found {
+		n.backlogs[sid] = append(pending, msg)
+		return true
+	}
+	if _, found := n.sessions[sid]; found {
+		return false
+	}
+	if n.backlogs == nil {
+		n.backlogs = map[int64][]*clusterpb.StreamMessage{}
+	}
+	n.backlogs[sid] = []*clusterpb.StreamMessage{msg}
+	go n.drainBacklog(sid, gateAddr)
+	return true
+}
+
+// drainBacklog creates the session and handles its queued messages until
+// there is none left
+func (n *Node) drainBacklog(sid int64, gateAddr string) {
+	if _, err := n.findOrCreateSession(sid, gateAddr); err != nil {
+		log.Println("Create session for stream messages failed", sid, err)
+	}
+	for {
+		n.Lock()
+		pending := n.backlogs[sid]
+		if len(pending) == 0 {
+			delete(n.backlogs, sid)
+			n.Unlock()
+			return
+		}
+		n.backlogs[sid] = []*clusterpb.StreamMessage{}
+		n.Unlock()
+
+		for _, msg := range pending {
+			n.handleStreamMessage(msg)
+		}
+	}
+}
+
+func (n *Node) handleStreamMessage(msg *clusterpb.StreamMessage) {
+	var err error
+	ctx := context.Background()
+	switch m := msg.Message.(type) {
+	case *clusterpb.StreamMessage_Request:
+		rctx, cancel := streamContext(m.Request.Metadata)
+		_, err = n.HandleRequest(rctx, m.Request)
+		cancel()
+	case *clusterpb.StreamMessage_Notify:
+		rctx, cancel := streamContext(m.Notify.Metadata)
+		_, err = n.HandleNotify(rctx, m.Notify)
+		cancel()
+	case *clusterpb.StreamMessage_Push:
+		err = n.deliver(m.Push.SessionId, pendingMessage{typ: message.Push, route: m.Push.Route, payload: m.Push.Data})
+	case *clusterpb.StreamMessage_Response:
+		err = n.deliver(m.Response.SessionId, pendingMessage{typ: message.Response, mid: m.Response.Id, payload: m.Response.Data, isError: m.Response.Error})
+	case *clusterpb.StreamMessage_Kick:
+		_, err = n.Kick(ctx, m.Kick)
+	case *clusterpb.StreamMessage_Close:
+		_, err = n.CloseSession(ctx, m.Close)
+	case *clusterpb.StreamMessage_Multicast:
+		n.multicast(m.Multicast.SessionIds, m.Multicast.Route, m.Multicast.Data)
+	}
+	if err != nil {
+		log.Println("Handle stream message failed", err)
+	}
+}
+
+// deliver sends the message to the client session connected to current node,
+// it never blocks the shared stream, the session whose delivery queue is full
+// is closed as a slow client
+func (n *Node) deliver(sid int64, msg pendingMessage) error {
+	s := n.findSession(sid)
+	if s == nil {
+		return fmt.Errorf("session not found: %v", sid)
+	}
+	switch a := s.NetworkEntity().(type) {
+	case *agent:
+		err := a.deliver(msg)
+		if err == ErrBufferExceed {
+			log.Printf("Close slow session, ID=%d, UID=%d", s.ID(), s.UID())
+			a.Close()
+		}
+		return err
+	case *parkedAgent:
+		return a.queue(msg)
+	}
+	return fmt.Errorf("session %v is not connected to current node", sid)
+}
+
+// Stream implements the MemberServer interface
+func (n *Node) Stream(ss clusterpb.Member_StreamServer) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	addrs := md.Get(streamAddrKey)
+	if len(addrs) == 0 {
+		return ErrInvalidStream
+	}
+	n.streamer.serve(addrs[0], ss)
+	return nil
+}
@@ -0,0 +1,259 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/revzim/amoeba/benchmark/io"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type ChatComponent struct{ component.Base }
+
+func (c *ChatComponent) Say(s *session.Session, ping *testdata.Ping) error {
+	return s.Push("message", &testdata.Pong{Content: ping.Content})
+}
+
+func (c *ChatComponent) Echo(s *session.Session, ping *testdata.Ping) error {
+	return s.Response(&testdata.Pong{Content: "echo " + ping.Content})
+}
+
+func (c *ChatComponent) Leave(s *session.Session, ping *testdata.Ping) error {
+	if err := s.Push("message", &testdata.Pong{Content: "bye " + ping.Content}); err != nil {
+		return err
+	}
+	s.Close()
+	return nil
+}
+
+func (s *nodeSuite) TestStreamTransport(c *C) {
+	masterComps := &component.Components{}
+	masterComps.Register(&MasterComponent{})
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: masterComps},
+		ServiceAddr: "127.0.0.1:19550",
+	}
+	c.Assert(master.Startup(), IsNil)
+
+	gate := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:19550",
+			ClientAddr:      "127.0.0.1:19552",
+			StreamTransport: true,
+			Components:      &component.Components{},
+		},
+		ServiceAddr: "127.0.0.1:19551",
+	}
+	c.Assert(gate.Startup(), IsNil)
+
+	chatComps := &component.Components{}
+	chatComps.Register(&ChatComponent{})
+	chat := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:19550",
+			StreamTransport: true,
+			Components:      chatComps,
+		},
+		ServiceAddr: "127.0.0.1:19553",
+	}
+	c.Assert(chat.Startup(), IsNil)
+
+	connector := io.NewConnector()
+	chWait := make(chan struct{})
+	connector.OnConnected(func() {
+		chWait <- struct{}{}
+	})
+	c.Assert(connector.Start("127.0.0.1:19552"), IsNil)
+	<-chWait
+
+	onResult := make(chan string, 64)
+	connector.On("message", func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	for i := 0; i < 50; i++ {
+		c.Assert(connector.Notify("ChatComponent.Say", &testdata.Ping{Content: fmt.Sprintf("message %d", i)}), IsNil)
+	}
+	for i := 0; i < 50; i++ {
+		c.Assert(strings.Contains(<-onResult, fmt.Sprintf("message %d", i)), IsTrue)
+	}
+
+	err := connector.Request("ChatComponent.Echo", &testdata.Ping{Content: "ping"}, func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(<-onResult, "echo ping"), IsTrue)
+
+	chat.Shutdown()
+	gate.Shutdown()
+	master.Shutdown()
+}
+
+func (s *nodeSuite) TestDeliverAfterClose(c *C) {
+	// the pending deliveries are dropped once the agent closed
+	cluster.DeliverAfterClose()
+}
+
+// slowGate is a gate whose session state of the first session is fetched
+// only after released
+type slowGate struct {
+	clusterpb.UnimplementedMemberServer
+	chRelease chan struct{}
+}
+
+func (g *slowGate) FetchSession(ctx context.Context, req *clusterpb.FetchSessionRequest) (*clusterpb.FetchSessionResponse, error) {
+	if req.SessionId == 1 {
+		<-g.chRelease
+	}
+	return &clusterpb.FetchSessionResponse{}, nil
+}
+
+func (s *nodeSuite) TestStreamSessionBacklog(c *C) {
+	chatComps := &component.Components{}
+	chatComps.Register(&ChatComponent{})
+	chat := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			StreamTransport: true,
+			Components:      chatComps,
+		},
+		ServiceAddr: "127.0.0.1:31572",
+	}
+	c.Assert(chat.Startup(), IsNil)
+	defer chat.Shutdown()
+
+	gate := &slowGate{chRelease: make(chan struct{})}
+	listener, err := net.Listen("tcp", "127.0.0.1:31573")
+	c.Assert(err, IsNil)
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:31572", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, cluster.StreamAddrKey, "127.0.0.1:31573")
+	stream, err := clusterpb.NewMemberClient(conn).Stream(ctx)
+	c.Assert(err, IsNil)
+
+	request := func(sid int64, mid uint64) {
+		c.Assert(stream.Send(&clusterpb.StreamMessage{Message: &clusterpb.StreamMessage_Request{Request: &clusterpb.RequestMessage{
+			GateAddr:  "127.0.0.1:31573",
+			SessionId: sid,
+			Id:        mid,
+			Route:     "ChatComponent.Echo",
+		}}}), IsNil)
+	}
+	response := func() *clusterpb.ResponseMessage {
+		msg, err := stream.Recv()
+		c.Assert(err, IsNil)
+		return msg.Message.(*clusterpb.StreamMessage_Response).Response
+	}
+
+	// the session fetching state does not block the other sessions
+	request(1, 1)
+	request(1, 2)
+	request(2, 1)
+	res := response()
+	c.Assert(res.SessionId, Equals, int64(2))
+
+	// the queued messages are handled in order once the session created
+	close(gate.chRelease)
+	c.Assert(response().Id, Equals, uint64(1))
+	request(1, 3)
+	c.Assert(response().Id, Equals, uint64(2))
+	c.Assert(response().Id, Equals, uint64(3))
+}
+
+// streamGate is a gate which records the kinds of messages received, either
+// over member stream or by unary calls
+type streamGate struct {
+	clusterpb.UnimplementedMemberServer
+	chReceived chan string
+}
+
+func (g *streamGate) Stream(ss clusterpb.Member_StreamServer) error {
+	for {
+		msg, err := ss.Recv()
+		if err != nil {
+			return nil
+		}
+		switch msg.Message.(type) {
+		case *clusterpb.StreamMessage_Push:
+			g.chReceived <- "push"
+		case *clusterpb.StreamMessage_Close:
+			g.chReceived <- "close"
+		case *clusterpb.StreamMessage_Multicast:
+			g.chReceived <- "multicast"
+		}
+	}
+}
+
+func (g *streamGate) CloseSession(context.Context, *clusterpb.CloseSessionRequest) (*clusterpb.CloseSessionResponse, error) {
+	g.chReceived <- "unary close"
+	return &clusterpb.CloseSessionResponse{}, nil
+}
+
+func (g *streamGate) HandleMulticast(context.Context, *clusterpb.MulticastMessage) (*clusterpb.MemberHandleResponse, error) {
+	g.chReceived <- "unary multicast"
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (s *nodeSuite) TestStreamCloseOrder(c *C) {
+	chatComps := &component.Components{}
+	chatComps.Register(&ChatComponent{})
+	chat := &cluster.Node{
+		Options: cluster.Options{
+			IsMaster:        true,
+			StreamTransport: true,
+			Components:      chatComps,
+		},
+		ServiceAddr: "127.0.0.1:31576",
+	}
+	c.Assert(chat.Startup(), IsNil)
+	defer chat.Shutdown()
+
+	gate := &streamGate{chReceived: make(chan string, 8)}
+	listener, err := net.Listen("tcp", "127.0.0.1:31577")
+	c.Assert(err, IsNil)
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:31576", grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = clusterpb.NewMemberClient(conn).HandleRequest(context.Background(), &clusterpb.RequestMessage{
+		GateAddr:  "127.0.0.1:31577",
+		SessionId: 1,
+		Route:     "ChatComponent.Leave",
+	})
+	c.Assert(err, IsNil)
+	expect := func(expected string) {
+		select {
+		case kind := <-gate.chReceived:
+			c.Assert(kind, Equals, expected)
+		case <-time.After(time.Second):
+			c.Fatalf("%s not received", expected)
+		}
+	}
+
+	// the close follows the push over the same stream
+	expect("push")
+	expect("close")
+	c.Assert(chat.Multicast("127.0.0.1:31577", []int64{2, 3}, "message", []byte("hello")), IsNil)
+	expect("multicast")
+}
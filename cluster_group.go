@@ -0,0 +1,304 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package amoeba
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/internal/log"
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/internal/runtime"
+	"github.com/revzim/amoeba/session"
+)
+
+type (
+	// MemberFilter represents a filter which was used to filter members when
+	// Multicast in ClusterGroup, the member will receive the message while filter
+	// returns true.
+	MemberFilter func(uid int64) bool
+
+	// memberKey identifies a client session in cluster
+	memberKey struct {
+		gateAddr string
+		sid      int64
+	}
+
+	// ClusterGroup represents a session group which can contain the sessions
+	// connected to different gate nodes. The members are tracked by the gate
+	// address and the session id in gate, data send to the group will be sent
+	// to each gate only once, and the gate pushes it to the sessions. The
+	// members leave the group once their sessions closed or their gates
+	// removed from cluster.
+	ClusterGroup struct {
+		sync.RWMutex
+		status  int32               // channel current status
+		name    string              // channel name
+		members map[memberKey]int64 // member => uid
+	}
+)
+
+// clusterGroups holds the working cluster groups, the members are removed once
+// their sessions closed or their gates removed from cluster
+var clusterGroups = struct {
+	sync.RWMutex
+	once   sync.Once
+	groups map[*ClusterGroup]struct{}
+	nodes  map[*cluster.Node]struct{} // nodes notifying the removed gates
+}{
+	groups: map[*ClusterGroup]struct{}{},
+	nodes:  map[*cluster.Node]struct{}{},
+}
+
+// NewClusterGroup returns a new cluster group instance
+func NewClusterGroup(n string) *ClusterGroup {
+	g := &ClusterGroup{
+		status:  groupStatusWorking,
+		name:    n,
+		members: map[memberKey]int64{},
+	}
+	clusterGroups.once.Do(func() {
+		session.Lifetime.OnClosed(leaveClosedSession)
+	})
+	clusterGroups.Lock()
+	clusterGroups.groups[g] = struct{}{}
+	clusterGroups.Unlock()
+	return g
+}
+
+// watchGates removes the members of the gates once they are removed from the
+// cluster known by node
+func watchGates(node *cluster.Node) {
+	clusterGroups.Lock()
+	defer clusterGroups.Unlock()
+	if _, found := clusterGroups.nodes[node]; found {
+		return
+	}
+	clusterGroups.nodes[node] = struct{}{}
+	node.OnMemberRemoved(leaveRemovedGate)
+}
+
+func leaveClosedSession(s *session.Session) {
+	node := runtime.CurrentNode
+	if node == nil {
+		return
+	}
+	gateAddr, sid := node.SessionOrigin(s)
+	key := memberKey{gateAddr: gateAddr, sid: sid}
+
+	clusterGroups.RLock()
+	defer clusterGroups.RUnlock()
+	for g := range clusterGroups.groups {
+		g.Lock()
+		delete(g.members, key)
+		g.Unlock()
+	}
+}
+
+func leaveRemovedGate(addr string) {
+	clusterGroups.RLock()
+	defer clusterGroups.RUnlock()
+	for g := range clusterGroups.groups {
+		g.Lock()
+		for key := range g.members {
+			if key.gateAddr == addr {
+				delete(g.members, key)
+			}
+		}
+		g.Unlock()
+	}
+}
+
+func (g *ClusterGroup) GetName() string {
+	return g.name
+}
+
+func (g *ClusterGroup) key(s *session.Session) (memberKey, error) {
+	node := runtime.CurrentNode
+	if node == nil {
+		return memberKey{}, ErrNodeNotRunning
+	}
+	watchGates(node)
+	gateAddr, sid := node.SessionOrigin(s)
+	return memberKey{gateAddr: gateAddr, sid: sid}, nil
+}
+
+// Add add session to group
+func (g *ClusterGroup) Add(s *session.Session) error {
+	if g.isClosed() {
+		return ErrClosedGroup
+	}
+	key, err := g.key(s)
+	if err != nil {
+		return err
+	}
+
+	if env.Debug {
+		log.Printf("Add session to cluster group %s, Gate=%s, ID=%d, UID=%d", g.name, key.gateAddr, key.sid, s.UID())
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.members[key]; ok {
+		return ErrSessionDuplication
+	}
+	g.members[key] = s.UID()
+	return nil
+}
+
+// Leave remove specified session from group
+func (g *ClusterGroup) Leave(s *session.Session) error {
+	if g.isClosed() {
+		return ErrClosedGroup
+	}
+	key, err := g.key(s)
+	if err != nil {
+		return err
+	}
+
+	if env.Debug {
+		log.Printf("Remove session from cluster group %s, UID=%d", g.name, s.UID())
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	delete(g.members, key)
+	return nil
+}
+
+// LeaveAll clear all sessions in the group
+func (g *ClusterGroup) LeaveAll() error {
+	if g.isClosed() {
+		return ErrClosedGroup
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.members = map[memberKey]int64{}
+	return nil
+}
+
+// Members returns all member's UID in current group
+func (g *ClusterGroup) Members() []int64 {
+	g.RLock()
+	defer g.RUnlock()
+
+	var members []int64
+	for _, uid := range g.members {
+		members = append(members, uid)
+	}
+	return members
+}
+
+// Contains check whether a UID is contained in current group or not
+func (g *ClusterGroup) Contains(uid int64) bool {
+	g.RLock()
+	defer g.RUnlock()
+
+	for _, u := range g.members {
+		if u == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// Count get current member amount in the group
+func (g *ClusterGroup) Count() int {
+	g.RLock()
+	defer g.RUnlock()
+
+	return len(g.members)
+}
+
+// Multicast push the message to the filtered members
+func (g *ClusterGroup) Multicast(route string, v interface{}, filter MemberFilter) error {
+	return g.multicast(route, v, filter)
+}
+
+// Broadcast push the message(s) to all members
+func (g *ClusterGroup) Broadcast(route string, v interface{}) error {
+	return g.multicast(route, v, nil)
+}
+
+func (g *ClusterGroup) multicast(route string, v interface{}, filter MemberFilter) error {
+	if g.isClosed() {
+		return ErrClosedGroup
+	}
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNodeNotRunning
+	}
+
+	data, err := message.SerializeWith(node.SerializerOf(route), v)
+	if err != nil {
+		return err
+	}
+
+	if env.Debug {
+		log.Printf("Multicast %s, Data=%+v", route, v)
+	}
+
+	gates := map[string][]int64{}
+	g.RLock()
+	for key, uid := range g.members {
+		if filter != nil && !filter(uid) {
+			continue
+		}
+		gates[key.gateAddr] = append(gates[key.gateAddr], key.sid)
+	}
+	g.RUnlock()
+
+	for gateAddr, sids := range gates {
+		if e := node.Multicast(gateAddr, sids, route, data); e != nil {
+			log.Printf("Cluster group multicast error, Gate=%s, Error=%s", gateAddr, e.Error())
+			err = e
+		}
+	}
+	return err
+}
+
+func (g *ClusterGroup) isClosed() bool {
+	return atomic.LoadInt32(&g.status) == groupStatusClosed
+}
+
+// Close destroy group, which will release all resource in the group
+func (g *ClusterGroup) Close() error {
+	if g.isClosed() {
+		return ErrCloseClosedGroup
+	}
+
+	atomic.StoreInt32(&g.status, groupStatusClosed)
+
+	clusterGroups.Lock()
+	delete(clusterGroups.groups, g)
+	clusterGroups.Unlock()
+
+	g.Lock()
+	g.members = map[memberKey]int64{}
+	g.Unlock()
+	return nil
+}
@@ -0,0 +1,222 @@
+package amoeba
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/revzim/amoeba/benchmark/io"
+	"github.com/revzim/amoeba/benchmark/testdata"
+	"github.com/revzim/amoeba/cluster"
+	"github.com/revzim/amoeba/cluster/clusterpb"
+	"github.com/revzim/amoeba/component"
+	"github.com/revzim/amoeba/internal/runtime"
+	"github.com/revzim/amoeba/scheduler"
+	"github.com/revzim/amoeba/serialize/json"
+	"github.com/revzim/amoeba/session"
+	"google.golang.org/grpc"
+)
+
+type RoomComponent struct {
+	component.Base
+	group *ClusterGroup
+}
+
+func (r *RoomComponent) Join(s *session.Session, _ *testdata.Ping) error {
+	if err := r.group.Add(s); err != nil {
+		return err
+	}
+	return s.Response(&testdata.Pong{Content: "joined"})
+}
+
+// TestMain runs the scheduler shared by tests, which can not be restarted
+func TestMain(m *testing.M) {
+	go scheduler.Sched()
+	code := m.Run()
+	scheduler.Close()
+	os.Exit(code)
+}
+
+func TestClusterGroup_Broadcast(t *testing.T) {
+	room := &RoomComponent{group: NewClusterGroup("room")}
+	comps := &component.Components{}
+	comps.Register(room)
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: comps},
+		ServiceAddr: "127.0.0.1:17550",
+	}
+	if err := master.Startup(); err != nil {
+		t.Fatal(err)
+	}
+	defer master.Shutdown()
+	runtime.CurrentNode = master
+	defer func() { runtime.CurrentNode = nil }()
+
+	var results []chan string
+	for _, addrs := range [][2]string{{"127.0.0.1:17551", "127.0.0.1:17552"}, {"127.0.0.1:17553", "127.0.0.1:17554"}} {
+		gate := &cluster.Node{
+			Options: cluster.Options{
+				AdvertiseAddr: "127.0.0.1:17550",
+				ClientAddr:    addrs[1],
+				Components:    &component.Components{},
+			},
+			ServiceAddr: addrs[0],
+		}
+		if err := gate.Startup(); err != nil {
+			t.Fatal(err)
+		}
+		defer gate.Shutdown()
+
+		connector := io.NewConnector()
+		chWait := make(chan struct{})
+		connector.OnConnected(func() {
+			chWait <- struct{}{}
+		})
+		// The client listener of gate starts asynchronously
+		var err error
+		for i := 0; i < 50; i++ {
+			if err = connector.Start(addrs[1]); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-chWait
+
+		onResult := make(chan string, 1)
+		connector.On("onMessage", func(data interface{}) {
+			onResult <- string(data.([]byte))
+		})
+		err = connector.Request("RoomComponent.Join", &testdata.Ping{}, func(data interface{}) {
+			onResult <- string(data.([]byte))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := <-onResult; !strings.Contains(r, "joined") {
+			t.Fatalf("unexpected response: %s", r)
+		}
+		results = append(results, onResult)
+	}
+
+	if room.group.Count() != 2 {
+		t.Fatalf("count expect: 2, got: %d", room.group.Count())
+	}
+	if err := room.group.Broadcast("onMessage", &testdata.Pong{Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, onResult := range results {
+		if r := <-onResult; !strings.Contains(r, "hello") {
+			t.Fatalf("unexpected message: %s", r)
+		}
+	}
+}
+
+// multicastCounter is a gate which counts the multicast rpcs received
+type multicastCounter struct {
+	clusterpb.UnimplementedMemberServer
+	mu         sync.Mutex
+	multicasts []*clusterpb.MulticastMessage
+	chResponse chan struct{}
+}
+
+func (g *multicastCounter) HandleMulticast(_ context.Context, req *clusterpb.MulticastMessage) (*clusterpb.MemberHandleResponse, error) {
+	g.mu.Lock()
+	g.multicasts = append(g.multicasts, req)
+	g.mu.Unlock()
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func (g *multicastCounter) HandleResponse(context.Context, *clusterpb.ResponseMessage) (*clusterpb.MemberHandleResponse, error) {
+	g.chResponse <- struct{}{}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+func waitCount(t *testing.T, g *ClusterGroup, count int) {
+	for i := 0; i < 50 && g.Count() != count; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if g.Count() != count {
+		t.Fatalf("count expect: %d, got: %d", count, g.Count())
+	}
+}
+
+func TestClusterGroup_Evict(t *testing.T) {
+	room := &RoomComponent{group: NewClusterGroup("room")}
+	defer room.group.Close()
+	comps := &component.Components{}
+	comps.Register(room, component.WithSerializer(json.NewSerializer()))
+	master := &cluster.Node{
+		Options:     cluster.Options{IsMaster: true, Components: comps},
+		ServiceAddr: "127.0.0.1:17560",
+	}
+	if err := master.Startup(); err != nil {
+		t.Fatal(err)
+	}
+	defer master.Shutdown()
+	runtime.CurrentNode = master
+	defer func() { runtime.CurrentNode = nil }()
+
+	gate := &multicastCounter{chResponse: make(chan struct{}, 1)}
+	listener, err := net.Listen("tcp", "127.0.0.1:17561")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	clusterpb.RegisterMemberServer(server, gate)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:17560", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := clusterpb.NewMemberClient(conn)
+	for sid := int64(1); sid <= 3; sid++ {
+		_, err := client.HandleRequest(context.Background(), &clusterpb.RequestMessage{
+			GateAddr:  "127.0.0.1:17561",
+			SessionId: sid,
+			Route:     "RoomComponent.Join",
+			Data:      []byte("{}"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-gate.chResponse
+	}
+
+	// the members of the same gate are pushed with one rpc, and the message
+	// is serialized by the serializer of route
+	if err := room.group.Broadcast("RoomComponent.onMessage", &testdata.Pong{Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	gate.mu.Lock()
+	if len(gate.multicasts) != 1 || len(gate.multicasts[0].SessionIds) != 3 {
+		t.Fatalf("unexpected multicasts: %v", gate.multicasts)
+	}
+	if data := string(gate.multicasts[0].Data); !strings.Contains(data, `"hello"`) {
+		t.Fatalf("unexpected data: %s", data)
+	}
+	gate.mu.Unlock()
+
+	// the member leaves once its session closed
+	_, err = client.SessionClosed(context.Background(), &clusterpb.SessionClosedRequest{SessionId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitCount(t, room.group, 2)
+
+	// all members of the gate leave once the gate removed
+	_, err = client.DelMember(context.Background(), &clusterpb.DelMemberRequest{ServiceAddr: "127.0.0.1:17561"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitCount(t, room.group, 0)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package component
+
+import (
+	"context"
+
+	"github.com/revzim/amoeba/session"
+)
+
+type (
+	// Invocation describes the message which is dispatching to handler, the
+	// interceptors could replace Arg with a value of the handler argument type
+	// before calling next, otherwise the request fails with CodeInternal because
+	// it is a fault of the interceptor rather than the client
+	Invocation struct {
+		Context context.Context  // context of request, see FromContext
+		Session *session.Session // session of message
+		ID      uint64           // message id, zero for notify
+		Route   string           // route of message
+		Handler *Handler         // handler resolved by route
+		Arg     interface{}      // decoded argument of handler
+	}
+
+	// Invoker invokes the next interceptor or the handler, the result is the
+	// response returned by handler, nil for the handler which does not return it
+	Invoker func(inv *Invocation) (interface{}, error)
+
+	// Interceptor intercepts the invocation of handler, it could short-circuit
+	// by returning an error without calling next, the error will be sent as the
+	// response, see package errors. The non-nil result will be sent as response
+	// of request even if the handler does not return it
+	Interceptor func(inv *Invocation, next Invoker) (interface{}, error)
+)
+
+// Intercept calls invoke through the interceptors of handler in order
+func (h *Handler) Intercept(inv *Invocation, invoke Invoker) (interface{}, error) {
+	for i := len(h.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := h.Interceptors[i], invoke
+		invoke = func(inv *Invocation) (interface{}, error) {
+			return interceptor(inv, next)
+		}
+	}
+	return invoke(inv)
+}
@@ -21,6 +21,7 @@
 package component
 
 import (
+	"context"
 	"reflect"
 	"unicode"
 	"unicode/utf8"
@@ -32,6 +33,7 @@ var (
 	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
 	typeOfBytes   = reflect.TypeOf(([]byte)(nil))
 	typeOfSession = reflect.TypeOf(session.New(nil))
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
 )
 
 func isExported(name string) bool {
@@ -56,22 +58,38 @@ func isHandlerMethod(method reflect.Method) bool {
 		return false
 	}
 
-	// Method needs three ins: receiver, *Session, []byte or pointer.
-	if mt.NumIn() != 3 {
+	// Method needs three ins: receiver, *Session, []byte or pointer, and an
+	// optional context.Context before *Session.
+	offset := 1
+	if isContextMethod(mt) {
+		offset = 2
+	}
+	if mt.NumIn() != offset+2 {
 		return false
 	}
 
-	// Method needs one outs: error
-	if mt.NumOut() != 1 {
+	// Method needs one outs: error, or two outs: []byte or pointer, error
+	switch mt.NumOut() {
+	case 1:
+	case 2:
+		if mt.Out(0).Kind() != reflect.Ptr && mt.Out(0) != typeOfBytes {
+			return false
+		}
+	default:
 		return false
 	}
 
-	if t1 := mt.In(1); t1.Kind() != reflect.Ptr || t1 != typeOfSession {
+	if t1 := mt.In(offset); t1.Kind() != reflect.Ptr || t1 != typeOfSession {
 		return false
 	}
 
-	if (mt.In(2).Kind() != reflect.Ptr && mt.In(2) != typeOfBytes) || mt.Out(0) != typeOfError {
+	if (mt.In(offset+1).Kind() != reflect.Ptr && mt.In(offset+1) != typeOfBytes) || mt.Out(mt.NumOut()-1) != typeOfError {
 		return false
 	}
 	return true
 }
+
+// isContextMethod reports whether the first argument of method is context.Context
+func isContextMethod(mt reflect.Type) bool {
+	return mt.NumIn() > 1 && mt.In(1) == typeOfContext
+}
@@ -20,11 +20,27 @@
 
 package component
 
+import (
+	"time"
+
+	"github.com/revzim/amoeba/ratelimit"
+	"github.com/revzim/amoeba/serialize"
+)
+
 type (
 	options struct {
-		name      string              // component name
-		nameFunc  func(string) string // rename handler name
-		schedName string              // schedName name
+		name       string               // component name
+		nameFunc   func(string) string  // rename handler name
+		schedName  string               // schedName name
+		balancer   string               // balancer name
+		serializer serialize.Serializer // serializer of handlers
+		timeout    time.Duration        // deadline of requests
+
+		interceptors      []Interceptor            // interceptors of all handlers
+		routeInterceptors map[string][]Interceptor // interceptors of handler by name
+
+		rateLimit       ratelimit.Limit            // rate limit of each handler
+		routeRateLimits map[string]ratelimit.Limit // rate limit of handler by name
 	}
 
 	// Option used to customize handler
@@ -52,3 +68,70 @@ func WithSchedulerName(name string) Option {
 		opt.schedName = name
 	}
 }
+
+// WithBalancer set the name of balancer which selects the member to serve the
+// sessions, see package balancer for the built-in balancers. The node fails to
+// start up if the name is not registered
+func WithBalancer(name string) Option {
+	return func(opt *options) {
+		opt.balancer = name
+	}
+}
+
+// WithSerializer sets the serializer of the service, the requests of handlers
+// and the responses will be serialized by it instead of the default serializer,
+// and so are the pushes which route prefixed with the service name
+func WithSerializer(serializer serialize.Serializer) Option {
+	return func(opt *options) {
+		opt.serializer = serializer
+	}
+}
+
+// WithTimeout sets the deadline of the requests of the service since they
+// arrived, the context passed to the context-aware handlers will be cancelled
+// once the deadline exceeded. The request forwarded by gate will take the
+// earlier one of the deadline set by gate and the timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(opt *options) {
+		opt.timeout = timeout
+	}
+}
+
+// WithInterceptors appends the interceptors of all handlers of the service,
+// which are called before the interceptors of handler
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(opt *options) {
+		opt.interceptors = append(opt.interceptors, interceptors...)
+	}
+}
+
+// WithRouteInterceptors appends the interceptors of the handler, the name is
+// the handler name rewritten by WithNameFunc if it is set
+func WithRouteInterceptors(name string, interceptors ...Interceptor) Option {
+	return func(opt *options) {
+		if opt.routeInterceptors == nil {
+			opt.routeInterceptors = map[string][]Interceptor{}
+		}
+		opt.routeInterceptors[name] = append(opt.routeInterceptors[name], interceptors...)
+	}
+}
+
+// WithRateLimit sets the rate limit of each handler of the service, which is
+// applied to the messages of route in each session
+func WithRateLimit(limit ratelimit.Limit) Option {
+	return func(opt *options) {
+		opt.rateLimit = limit
+	}
+}
+
+// WithRouteRateLimit sets the rate limit of the handler, which overrides the
+// limit set by WithRateLimit, the name is the handler name rewritten by
+// WithNameFunc if it is set
+func WithRouteRateLimit(name string, limit ratelimit.Limit) Option {
+	return func(opt *options) {
+		if opt.routeRateLimits == nil {
+			opt.routeRateLimits = map[string]ratelimit.Limit{}
+		}
+		opt.routeRateLimits[name] = limit
+	}
+}
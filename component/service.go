@@ -23,6 +23,10 @@ package component
 import (
 	"errors"
 	"reflect"
+	"time"
+
+	"github.com/revzim/amoeba/ratelimit"
+	"github.com/revzim/amoeba/serialize"
 )
 
 type (
@@ -32,17 +36,28 @@ type (
 		Method   reflect.Method // method stub
 		Type     reflect.Type   // arg type of method
 		IsRawArg bool           // whether the data need to unserialize
+		IsCtxArg bool           // whether the first argument is context.Context
+		IsReply  bool           // whether the response is returned by method
+
+		Serializer serialize.Serializer // serializer of handler, nil for the default one
+		Timeout    time.Duration        // deadline of request since it arrived, zero for no deadline
+		RateLimit  ratelimit.Limit      // rate limit of route in each session
+
+		Interceptors []Interceptor // interceptors of service and handler in order
 	}
 
 	// Service implements a specific service, some of it's methods will be
 	// called when the correspond events is occurred.
 	Service struct {
-		Name      string              // name of service
-		Type      reflect.Type        // type of the receiver
-		Receiver  reflect.Value       // receiver of methods for the service
-		Handlers  map[string]*Handler // registered methods
-		SchedName string              // name of scheduler variable in session data
-		Options   options             // options
+		Name       string               // name of service
+		Type       reflect.Type         // type of the receiver
+		Receiver   reflect.Value        // receiver of methods for the service
+		Handlers   map[string]*Handler  // registered methods
+		SchedName  string               // name of scheduler variable in session data
+		Balancer   string               // name of balancer which selects remote member
+		Serializer serialize.Serializer // serializer of handlers, nil for the default one
+		Timeout    time.Duration        // deadline of requests, zero for no deadline
+		Options    options              // options
 	}
 )
 
@@ -63,6 +78,9 @@ func NewService(comp Component, opts []Option) *Service {
 		s.Name = reflect.Indirect(s.Receiver).Type().Name()
 	}
 	s.SchedName = s.Options.schedName
+	s.Balancer = s.Options.balancer
+	s.Serializer = s.Options.serializer
+	s.Timeout = s.Options.timeout
 
 	return s
 }
@@ -75,15 +93,33 @@ func (s *Service) suitableHandlerMethods(typ reflect.Type) map[string]*Handler {
 		mt := method.Type
 		mn := method.Name
 		if isHandlerMethod(method) {
+			arg := mt.In(mt.NumIn() - 1)
 			raw := false
-			if mt.In(2) == typeOfBytes {
+			if arg == typeOfBytes {
 				raw = true
 			}
 			// rewrite handler name
 			if s.Options.nameFunc != nil {
 				mn = s.Options.nameFunc(mn)
 			}
-			methods[mn] = &Handler{Method: method, Type: mt.In(2), IsRawArg: raw}
+			methods[mn] = &Handler{
+				Method:     method,
+				Type:       arg,
+				IsRawArg:   raw,
+				IsCtxArg:   isContextMethod(mt),
+				IsReply:    mt.NumOut() == 2,
+				Serializer: s.Serializer,
+				Timeout:    s.Timeout,
+				RateLimit:  s.Options.rateLimit,
+			}
+			if limit, found := s.Options.routeRateLimits[mn]; found {
+				methods[mn].RateLimit = limit
+			}
+			if n := len(s.Options.interceptors) + len(s.Options.routeInterceptors[mn]); n > 0 {
+				interceptors := make([]Interceptor, 0, n)
+				interceptors = append(interceptors, s.Options.interceptors...)
+				methods[mn].Interceptors = append(interceptors, s.Options.routeInterceptors[mn]...)
+			}
 		}
 	}
 	return methods
@@ -95,6 +131,9 @@ func (s *Service) suitableHandlerMethods(typ reflect.Type) map[string]*Handler {
 // - two arguments, both of exported type
 // - the first argument is *session.Session
 // - the second argument is []byte or a pointer
+// - an optional context.Context argument before *session.Session
+// - returns error, or the response([]byte or a pointer) and error, and the
+// returned response will be sent to the request automatically
 func (s *Service) ExtractHandler() error {
 	typeName := reflect.Indirect(s.Receiver).Type().Name()
 	if typeName == "" {
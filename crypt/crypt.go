@@ -72,6 +72,17 @@ func (c *Crypt) newHash(b []byte) string {
 }
 
 func (c *Crypt) Encrypt(data []byte) ([]byte, error) {
+	return c.EncryptWithAD(data, nil)
+}
+
+func (c *Crypt) Decrypt(data []byte) ([]byte, error) {
+	return c.DecryptWithAD(data, nil)
+}
+
+// EncryptWithAD encrypts the data and authenticates the additional data which
+// is not included in the result, the same additional data is required to
+// decrypt it
+func (c *Crypt) EncryptWithAD(data, ad []byte) ([]byte, error) {
 	block, err := aes.NewCipher([]byte(c.newHash(c.key)))
 	if err != nil {
 		return nil, err
@@ -84,11 +95,12 @@ func (c *Crypt) Encrypt(data []byte) ([]byte, error) {
 	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, err
 	}
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
+	ciphertext := gcm.Seal(nonce, nonce, data, ad)
 	return ciphertext, nil
 }
 
-func (c *Crypt) Decrypt(data []byte) ([]byte, error) {
+// DecryptWithAD decrypts the data encrypted by EncryptWithAD
+func (c *Crypt) DecryptWithAD(data, ad []byte) ([]byte, error) {
 	key := []byte(c.newHash(c.key))
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -103,7 +115,7 @@ func (c *Crypt) Decrypt(data []byte) ([]byte, error) {
 		return nil, errors.New(fmt.Sprintf("nonce size > len data %d > %d", nonceSize, len(data)))
 	}
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, ad)
 	if err != nil {
 		return nil, err
 	}
@@ -139,12 +151,17 @@ func (c *Crypt) initHash(str, salt string) string {
 	return base64.StdEncoding.EncodeToString(buf.Bytes())
 }
 
+// NewHashAndSalt returns the legacy SHA-1 hash of password.
+//
+// Deprecated: the hash is too fast to resist brute force, use HashPassword.
 func (c *Crypt) NewHashAndSalt(str string) (hash, salt string) {
 	salt = strings.Replace(uuid.New().String(), "-", "", -1)
 	hash = c.initHash(str, salt)
 	return hash, salt
 }
 
+// VerifyPassword verifies the legacy SHA-1 hash, see Verify to accept both the
+// legacy and encoded hashes.
 func (c *Crypt) VerifyPassword(pwd, salt, hash string) bool {
 	return c.initHash(pwd, salt) == hash
 }
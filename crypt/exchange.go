@@ -0,0 +1,190 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/session"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// X25519 is the name of key exchange algorithm negotiated in handshake
+const X25519 = "x25519"
+
+// StageName is the name of the pipeline stages installed by InstallStages
+const StageName = "crypt"
+
+// sessionKeyInfo binds the derived keys to their usage
+var sessionKeyInfo = []byte("amoeba session keys v1")
+
+// Errors of key exchange and session cipher
+var (
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	ErrNoSessionCipher  = errors.New("session has no cipher negotiated")
+	ErrCiphertext       = errors.New("ciphertext too short")
+)
+
+type (
+	// KeyPair is an ephemeral X25519 key pair, a new key pair should be
+	// generated for each handshake
+	KeyPair struct {
+		private []byte
+		Public  []byte
+	}
+
+	// SessionCipher encrypts the payloads of a session with AES-256-GCM, the
+	// keys of two directions are derived from the X25519 shared secret by HKDF
+	SessionCipher struct {
+		seal cipher.AEAD
+		open cipher.AEAD
+	}
+)
+
+// GenerateKeyPair returns a new ephemeral key pair
+func GenerateKeyPair() (*KeyPair, error) {
+	private := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, private); err != nil {
+		return nil, err
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{private: private, Public: public}, nil
+}
+
+// ServerCipher returns the cipher of server which exchanged the public key
+// with the client
+func (k *KeyPair) ServerCipher(clientPublic []byte) (*SessionCipher, error) {
+	return k.cipher(clientPublic, k.Public, false)
+}
+
+// ClientCipher returns the cipher of client which exchanged the public key
+// with the server
+func (k *KeyPair) ClientCipher(serverPublic []byte) (*SessionCipher, error) {
+	return k.cipher(k.Public, serverPublic, true)
+}
+
+func (k *KeyPair) cipher(clientPublic, serverPublic []byte, isClient bool) (*SessionCipher, error) {
+	peer := serverPublic
+	if !isClient {
+		peer = clientPublic
+	}
+	if len(peer) != curve25519.PointSize {
+		return nil, ErrInvalidPublicKey
+	}
+	// X25519 rejects the low order points which produce all-zero secret
+	secret, err := curve25519.X25519(k.private, peer)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	salt := make([]byte, 0, len(clientPublic)+len(serverPublic))
+	salt = append(append(salt, clientPublic...), serverPublic...)
+	keys := make([]byte, 64)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, sessionKeyInfo), keys); err != nil {
+		return nil, err
+	}
+
+	// the first key encrypts the payloads sent by client, the second one
+	// encrypts the payloads sent by server
+	upstream, err := newGCM(keys[:32])
+	if err != nil {
+		return nil, err
+	}
+	downstream, err := newGCM(keys[32:])
+	if err != nil {
+		return nil, err
+	}
+	if isClient {
+		return &SessionCipher{seal: upstream, open: downstream}, nil
+	}
+	return &SessionCipher{seal: downstream, open: upstream}, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts the plaintext, the random nonce is prepended to the result.
+// The result could be replayed on the same connection, see SealSequenced
+func (c *SessionCipher) Seal(plaintext []byte) ([]byte, error) {
+	return c.SealWithAD(plaintext, nil)
+}
+
+// Open decrypts the ciphertext produced by Seal of peer
+func (c *SessionCipher) Open(ciphertext []byte) ([]byte, error) {
+	return c.OpenWithAD(ciphertext, nil)
+}
+
+// SealWithAD encrypts the plaintext and authenticates the additional data,
+// the random nonce is prepended to the result
+func (c *SessionCipher) SealWithAD(plaintext, ad []byte) ([]byte, error) {
+	size := c.seal.NonceSize()
+	buf := make([]byte, size, size+len(plaintext)+c.seal.Overhead())
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return nil, err
+	}
+	return c.seal.Seal(buf, buf, plaintext, ad), nil
+}
+
+// OpenWithAD decrypts the ciphertext produced by SealWithAD of peer with the
+// same additional data
+func (c *SessionCipher) OpenWithAD(ciphertext, ad []byte) ([]byte, error) {
+	size := c.open.NonceSize()
+	if len(ciphertext) < size+c.open.Overhead() {
+		return nil, ErrCiphertext
+	}
+	return c.open.Open(nil, ciphertext[:size], ciphertext[size:], ad)
+}
+
+// EncryptStage is the outbound pipeline stage which encrypts the payload with
+// the cipher of session
+func EncryptStage(s *session.Session, msg *pipeline.Message) error {
+	c := s.Cipher()
+	if c == nil {
+		return ErrNoSessionCipher
+	}
+	data, err := c.Seal(msg.Data)
+	if err != nil {
+		return err
+	}
+	msg.Data = data
+	return nil
+}
+
+// DecryptStage is the inbound pipeline stage which decrypts the payload with
+// the cipher of session
+func DecryptStage(s *session.Session, msg *pipeline.Message) error {
+	c := s.Cipher()
+	if c == nil {
+		return ErrNoSessionCipher
+	}
+	data, err := c.Open(msg.Data)
+	if err != nil {
+		return err
+	}
+	msg.Data = data
+	return nil
+}
+
+// InstallStages pushes the encryption stages into the pipeline, the outbound
+// one is the last stage and the inbound one is the first stage, so the other
+// stages see the plaintext. The inbound stage is bound to the client connection,
+// which requires the session cipher negotiated in handshake. The stages could
+// be removed by StageName. The payloads could be replayed on the same
+// connection, use InstallSequencedStages to reject them
+func InstallStages(p pipeline.Pipeline) {
+	p.Outbound().PushBackNamed(StageName, EncryptStage)
+	p.Inbound().PushFrontConnection(StageName, pipeline.RequireCipher, DecryptStage)
+}
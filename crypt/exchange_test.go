@@ -0,0 +1,55 @@
+package crypt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeyExchange(t *testing.T) {
+	server, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := server.ServerCipher(client.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := client.ClientCipher(server.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("hello amoeba")
+	up, err := cc.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := sc.Open(up); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("open upstream failed: %v, %q", err, got)
+	}
+	down, err := sc.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cc.Open(down); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("open downstream failed: %v, %q", err, got)
+	}
+
+	// the keys of two directions are different
+	if _, err := cc.Open(up); err == nil {
+		t.Fatal("upstream ciphertext should not be opened by client")
+	}
+	if _, err := sc.Open(up[:4]); err != ErrCiphertext {
+		t.Fatalf("expect %v, got %v", ErrCiphertext, err)
+	}
+	if _, err := server.ServerCipher(make([]byte, 32)); err != ErrInvalidPublicKey {
+		t.Fatalf("expect %v, got %v", ErrInvalidPublicKey, err)
+	}
+	if _, err := server.ServerCipher([]byte("short")); err != ErrInvalidPublicKey {
+		t.Fatalf("expect %v, got %v", ErrInvalidPublicKey, err)
+	}
+}
@@ -0,0 +1,192 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+// Errors of encoded password hashes
+var (
+	ErrUnknownHash = errors.New("unknown password hash algorithm")
+	ErrInvalidHash = errors.New("invalid encoded password hash")
+)
+
+type (
+	// Argon2Params is the cost parameters of argon2id, Memory is in KiB
+	Argon2Params struct {
+		Time    uint32
+		Memory  uint32
+		Threads uint8
+		SaltLen uint32
+		KeyLen  uint32
+	}
+
+	// PasswordHasher hashes the passwords into self-describing strings, which
+	// hold the algorithm, parameters and salt, so the hashes produced with the
+	// old parameters could still be verified
+	PasswordHasher struct {
+		Algorithm  string       // Argon2id or Bcrypt
+		Argon2     Argon2Params // used if Algorithm is Argon2id
+		BcryptCost int          // used if Algorithm is Bcrypt
+	}
+)
+
+// DefaultArgon2Params is the recommended parameters of RFC 9106 for the
+// memory constrained environments
+var DefaultArgon2Params = Argon2Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+// DefaultPasswordHasher is used by HashPassword and NeedsRehash
+var DefaultPasswordHasher = PasswordHasher{
+	Algorithm:  Argon2id,
+	Argon2:     DefaultArgon2Params,
+	BcryptCost: bcrypt.DefaultCost,
+}
+
+// HashPassword hashes the password with DefaultPasswordHasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// NeedsRehash reports whether the hash should be replaced by the one produced
+// by DefaultPasswordHasher, see PasswordHasher.NeedsRehash
+func NeedsRehash(encoded string) bool {
+	return DefaultPasswordHasher.NeedsRehash(encoded)
+}
+
+// Hash returns the encoded hash of password, argon2id hashes are encoded in
+// PHC string format, e.g. $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>, and
+// bcrypt hashes in the modular crypt format, e.g. $2a$10$<salt><key>
+func (h PasswordHasher) Hash(password string) (string, error) {
+	switch h.Algorithm {
+	case Argon2id:
+		p := h.Argon2
+		salt := make([]byte, p.SaltLen)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+		return encodeArgon2(p, salt, key), nil
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+	return "", ErrUnknownHash
+}
+
+// NeedsRehash reports whether the encoded hash is produced by another algorithm
+// or with other parameters, the legacy SHA-1 hashes always need rehash. The
+// password should be hashed again after it has been verified on login
+func (h PasswordHasher) NeedsRehash(encoded string) bool {
+	switch algorithmOf(encoded) {
+	case Argon2id:
+		if h.Algorithm != Argon2id {
+			return true
+		}
+		p, _, _, err := decodeArgon2(encoded)
+		return err != nil || p != h.Argon2
+	case Bcrypt:
+		if h.Algorithm != Bcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.BcryptCost
+	}
+	return true
+}
+
+// VerifyHash reports whether the password matches the encoded hash produced by
+// PasswordHasher, the legacy SHA-1 hashes are verified by Crypt.VerifyPassword
+func VerifyHash(password, encoded string) (bool, error) {
+	switch algorithmOf(encoded) {
+	case Argon2id:
+		p, salt, key, err := decodeArgon2(encoded)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	case Bcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	return false, ErrUnknownHash
+}
+
+// Verify checks the password against both the encoded hashes and the legacy
+// SHA-1 hashes, the salt is only used by the legacy hashes. It could be used
+// to upgrade the legacy hashes on login with NeedsRehash
+func (c *Crypt) Verify(pwd, salt, hash string) bool {
+	if algorithmOf(hash) == "" {
+		return c.VerifyPassword(pwd, salt, hash)
+	}
+	ok, err := VerifyHash(pwd, hash)
+	return err == nil && ok
+}
+
+// algorithmOf returns the algorithm of encoded hash, empty for legacy hashes
+func algorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$"+Argon2id+"$"):
+		return Argon2id
+	case strings.HasPrefix(encoded, "$2a$"),
+		strings.HasPrefix(encoded, "$2b$"),
+		strings.HasPrefix(encoded, "$2y$"):
+		return Bcrypt
+	}
+	return ""
+}
+
+func encodeArgon2(p Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", Argon2id, argon2.Version,
+		p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2(encoded string) (p Argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 || p.Time == 0 || p.Threads == 0 {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLen, p.KeyLen = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,75 @@
+package crypt
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	argon := PasswordHasher{
+		Algorithm: Argon2id,
+		Argon2:    Argon2Params{Time: 1, Memory: 1024, Threads: 1, SaltLen: 16, KeyLen: 32},
+	}
+	bcryptHasher := PasswordHasher{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost}
+
+	for _, h := range []PasswordHasher{argon, bcryptHasher} {
+		hash, err := h.Hash("passw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := VerifyHash("passw0rd", hash); err != nil || !ok {
+			t.Fatalf("%s: verify failed: %v", h.Algorithm, err)
+		}
+		if ok, err := VerifyHash("password", hash); err != nil || ok {
+			t.Fatalf("%s: wrong password verified: %v", h.Algorithm, err)
+		}
+		if h.NeedsRehash(hash) {
+			t.Fatalf("%s: hash with current parameters needs no rehash", h.Algorithm)
+		}
+	}
+
+	hash, err := argon.Hash("passw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected encoded hash: %s", hash)
+	}
+	stronger := argon
+	stronger.Argon2.Time = 2
+	if !stronger.NeedsRehash(hash) || !bcryptHasher.NeedsRehash(hash) {
+		t.Fatal("hash with other parameters or algorithm needs rehash")
+	}
+
+	if _, err := VerifyHash("passw0rd", "$argon2id$v=19$m=1024$salt$key"); err != ErrInvalidHash {
+		t.Fatalf("expect %v, got %v", ErrInvalidHash, err)
+	}
+	if _, err := VerifyHash("passw0rd", "$md5$salt$key"); err != ErrUnknownHash {
+		t.Fatalf("expect %v, got %v", ErrUnknownHash, err)
+	}
+}
+
+func TestLegacyUpgrade(t *testing.T) {
+	c := New([]byte("secret"), 0)
+	legacy, salt := c.NewHashAndSalt("passw0rd")
+	if !c.VerifyPassword("passw0rd", salt, legacy) || !c.Verify("passw0rd", salt, legacy) {
+		t.Fatal("legacy hash should be verified")
+	}
+	if c.Verify("password", salt, legacy) {
+		t.Fatal("wrong password verified")
+	}
+	if !NeedsRehash(legacy) {
+		t.Fatal("legacy hash needs rehash")
+	}
+
+	h := PasswordHasher{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost}
+	upgraded, err := h.Hash("passw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verify("passw0rd", "", upgraded) || c.Verify("password", "", upgraded) {
+		t.Fatal("upgraded hash should be verified")
+	}
+}
@@ -0,0 +1,235 @@
+package crypt
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/session"
+)
+
+// ReplayWindow is the number of latest sequences remembered by receiver, the
+// messages reordered within the window are accepted once
+const ReplayWindow = 64
+
+// SequenceStageName is the name of the pipeline stages installed by
+// InstallSequenceStages
+const SequenceStageName = "crypt-sequence"
+
+// the transport value keys of the sequence states of session, the stages of
+// process-wide key and session cipher keep their own sequences
+const (
+	sequenceKey        = "crypt.sequences"
+	sessionSequenceKey = "crypt.session-sequences"
+)
+
+// Directions of messages, the direction is authenticated with the sequence, so
+// the messages sent by server can not be reflected back to server
+const (
+	Upstream   byte = 'u' // client to server
+	Downstream byte = 'd' // server to client
+)
+
+// Errors of sequenced messages
+var (
+	ErrReplayed = errors.New("message replayed, out of window or not sealed for the connection")
+	ErrSequence = errors.New("message sequence missing")
+	ErrNoNonce  = errors.New("session without nonce of connection")
+)
+
+type (
+	// sequences holds the per-direction sequences of a connection, the
+	// sequences start from 1 for each handshake
+	sequences struct {
+		sync.Mutex
+		sent   uint64 // sequence of the last sent message
+		window window
+	}
+
+	// window is a sliding window of received sequences, the nth bit of
+	// bitmap represents whether the sequence highest-n is received
+	window struct {
+		highest uint64
+		bitmap  uint64
+	}
+)
+
+func sequencesOf(s *session.Session, key string) *sequences {
+	return s.TransportValue(key, func() interface{} { return &sequences{} }).(*sequences)
+}
+
+// check reports whether the sequence is neither received nor out of window
+func (w *window) check(seq uint64) bool {
+	if seq == 0 {
+		return false
+	}
+	if seq > w.highest {
+		return true
+	}
+	diff := w.highest - seq
+	return diff < ReplayWindow && w.bitmap&(1<<diff) == 0
+}
+
+// accept marks the sequence checked by check as received
+func (w *window) accept(seq uint64) {
+	if seq > w.highest {
+		shift := seq - w.highest
+		if shift >= ReplayWindow {
+			w.bitmap = 1
+		} else {
+			w.bitmap = w.bitmap<<shift | 1
+		}
+		w.highest = seq
+		return
+	}
+	w.bitmap |= 1 << (w.highest - seq)
+}
+
+// sequenceAD returns the additional data of sequenced message, the nonce binds
+// the message to the connection, see session.Nonce
+func sequenceAD(direction byte, nonce, seq []byte) []byte {
+	ad := make([]byte, 0, 1+len(nonce)+len(seq))
+	ad = append(ad, direction)
+	ad = append(ad, nonce...)
+	return append(ad, seq...)
+}
+
+// SealSequenced encrypts the data with the next outbound sequence of session,
+// the sequence is prepended to the result and authenticated with the nonce of
+// session as the additional data of AES-GCM
+func (c *Crypt) SealSequenced(s *session.Session, direction byte, data []byte) ([]byte, error) {
+	return sealSequenced(s, sequenceKey, direction, data, c.EncryptWithAD)
+}
+
+// OpenSequenced decrypts the data sealed by SealSequenced of peer, the replayed
+// messages, the messages fall behind the window and the messages which fail
+// authentication, e.g. sealed for the previous connection, are rejected. The
+// session without nonce is rejected, its messages could be replayed on any
+// connection without nonce
+func (c *Crypt) OpenSequenced(s *session.Session, direction byte, data []byte) ([]byte, error) {
+	return openSequenced(s, sequenceKey, direction, data, c.DecryptWithAD)
+}
+
+// SealSequenced encrypts the data with the session cipher like
+// Crypt.SealSequenced, the session must hold the nonce of connection
+func (c *SessionCipher) SealSequenced(s *session.Session, direction byte, data []byte) ([]byte, error) {
+	return sealSequenced(s, sessionSequenceKey, direction, data, c.SealWithAD)
+}
+
+// OpenSequenced decrypts the data sealed by SessionCipher.SealSequenced of
+// peer, the messages are rejected like Crypt.OpenSequenced
+func (c *SessionCipher) OpenSequenced(s *session.Session, direction byte, data []byte) ([]byte, error) {
+	return openSequenced(s, sessionSequenceKey, direction, data, c.OpenWithAD)
+}
+
+func sealSequenced(s *session.Session, key string, direction byte, data []byte, seal func(plaintext, ad []byte) ([]byte, error)) ([]byte, error) {
+	nonce := s.Nonce()
+	if len(nonce) == 0 {
+		return nil, ErrNoNonce
+	}
+	seqs := sequencesOf(s, key)
+	seqs.Lock()
+	defer seqs.Unlock()
+
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, seqs.sent+1)
+	ciphertext, err := seal(data, sequenceAD(direction, nonce, seq))
+	if err != nil {
+		return nil, err
+	}
+	seqs.sent++
+	return append(seq, ciphertext...), nil
+}
+
+func openSequenced(s *session.Session, key string, direction byte, data []byte, open func(ciphertext, ad []byte) ([]byte, error)) ([]byte, error) {
+	nonce := s.Nonce()
+	if len(nonce) == 0 {
+		return nil, ErrNoNonce
+	}
+	if len(data) < 8 {
+		return nil, ErrSequence
+	}
+	seqs := sequencesOf(s, key)
+	seqs.Lock()
+	defer seqs.Unlock()
+
+	seq := binary.BigEndian.Uint64(data)
+	if !seqs.window.check(seq) {
+		return nil, ErrReplayed
+	}
+	plaintext, err := open(data[8:], sequenceAD(direction, nonce, data[:8]))
+	if err != nil {
+		return nil, ErrReplayed
+	}
+	// only the authenticated sequences move the window
+	seqs.window.accept(seq)
+	return plaintext, nil
+}
+
+// InstallSequenceStages pushes the sequenced encryption stages into the
+// pipeline like InstallStages, isServer decides the directions of the
+// outbound and inbound messages. The sequences restart in each handshake,
+// including the one resumed the session, and the messages are bound to the
+// nonce exchanged in handshake, so they can not be replayed on new connection.
+// The stages fail with ErrNoNonce for the session without nonce
+func (c *Crypt) InstallSequenceStages(p pipeline.Pipeline, isServer bool) {
+	outbound, inbound := Upstream, Downstream
+	if isServer {
+		outbound, inbound = Downstream, Upstream
+	}
+	p.Outbound().PushBackNamed(SequenceStageName, func(s *session.Session, msg *pipeline.Message) error {
+		data, err := c.SealSequenced(s, outbound, msg.Data)
+		if err != nil {
+			return err
+		}
+		msg.Data = data
+		return nil
+	})
+	p.Inbound().PushFrontConnection(SequenceStageName, pipeline.RequireNonce, func(s *session.Session, msg *pipeline.Message) error {
+		data, err := c.OpenSequenced(s, inbound, msg.Data)
+		if err != nil {
+			return err
+		}
+		msg.Data = data
+		return nil
+	})
+}
+
+// InstallSequencedStages pushes the encryption stages of session cipher like
+// InstallStages, but the payloads are bound to the sequences and the nonce of
+// connection like InstallSequenceStages, so they can not be replayed on the
+// same connection either. The stages replace the ones of InstallStages, and
+// the inbound stage requires both key exchange and nonce in handshake. The
+// stages fail with ErrNoSessionCipher for the cipher not sealing additional
+// data, see SessionCipher
+func InstallSequencedStages(p pipeline.Pipeline, isServer bool) {
+	outbound, inbound := Upstream, Downstream
+	if isServer {
+		outbound, inbound = Downstream, Upstream
+	}
+	p.Outbound().PushBackNamed(StageName, func(s *session.Session, msg *pipeline.Message) error {
+		c, ok := s.Cipher().(*SessionCipher)
+		if !ok {
+			return ErrNoSessionCipher
+		}
+		data, err := c.SealSequenced(s, outbound, msg.Data)
+		if err != nil {
+			return err
+		}
+		msg.Data = data
+		return nil
+	})
+	p.Inbound().PushFrontConnection(StageName, pipeline.RequireCipher|pipeline.RequireNonce, func(s *session.Session, msg *pipeline.Message) error {
+		c, ok := s.Cipher().(*SessionCipher)
+		if !ok {
+			return ErrNoSessionCipher
+		}
+		data, err := c.OpenSequenced(s, inbound, msg.Data)
+		if err != nil {
+			return err
+		}
+		msg.Data = data
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+package crypt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/session"
+)
+
+func TestWindow(t *testing.T) {
+	w := window{}
+	accept := func(seq uint64) bool {
+		if !w.check(seq) {
+			return false
+		}
+		w.accept(seq)
+		return true
+	}
+	cases := []struct {
+		seq      uint64
+		accepted bool
+	}{
+		{0, false},
+		{1, true},
+		{1, false},
+		{3, true},
+		{2, true},
+		{2, false},
+		{100, true},
+		{100 - ReplayWindow + 1, true},
+		{100 - ReplayWindow, false},
+		{99, true},
+		{99, false},
+		{1000, true},
+		{100, false},
+	}
+	for _, c := range cases {
+		if got := accept(c.seq); got != c.accepted {
+			t.Fatalf("sequence %d: expect accepted %v, got %v", c.seq, c.accepted, got)
+		}
+	}
+}
+
+func TestSequenced(t *testing.T) {
+	c := New([]byte("secret"), 0)
+	server, client := session.New(nil), session.New(nil)
+	server.SetNonce([]byte("connection-1"))
+	client.SetNonce([]byte("connection-1"))
+
+	plaintext := []byte("hello amoeba")
+	first, err := c.SealSequenced(client, Upstream, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.SealSequenced(client, Upstream, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.OpenSequenced(server, Upstream, second); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("open failed: %v, %q", err, got)
+	}
+	if _, err := c.OpenSequenced(server, Upstream, first); err != nil {
+		t.Fatalf("reordered message should be accepted: %v", err)
+	}
+	if _, err := c.OpenSequenced(server, Upstream, first); err != ErrReplayed {
+		t.Fatalf("expect %v, got %v", ErrReplayed, err)
+	}
+
+	// the sequence and direction are authenticated
+	third, err := c.SealSequenced(client, Upstream, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append([]byte{}, third...)
+	forged[7]++
+	if _, err := c.OpenSequenced(server, Upstream, forged); err == nil {
+		t.Fatal("forged sequence should be rejected")
+	}
+	if _, err := c.OpenSequenced(server, Downstream, third); err == nil {
+		t.Fatal("reflected message should be rejected")
+	}
+	if _, err := c.OpenSequenced(server, Upstream, third); err != nil {
+		t.Fatalf("rejected messages should not move the window: %v", err)
+	}
+
+	// the sequences restart in new handshake, and the messages sealed for
+	// the previous connection can not be replayed
+	server.SetHandshakeInfo(&session.HandshakeInfo{})
+	server.SetNonce([]byte("connection-2"))
+	if _, err := c.OpenSequenced(server, Upstream, first); err != ErrReplayed {
+		t.Fatalf("expect %v, got %v", ErrReplayed, err)
+	}
+	client.SetHandshakeInfo(&session.HandshakeInfo{})
+	client.SetNonce([]byte("connection-2"))
+	resumed, err := c.SealSequenced(client, Upstream, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OpenSequenced(server, Upstream, resumed); err != nil {
+		t.Fatalf("sequences should be reset: %v", err)
+	}
+	if _, err := c.OpenSequenced(server, Upstream, []byte{1}); err != ErrSequence {
+		t.Fatalf("expect %v, got %v", ErrSequence, err)
+	}
+}
+
+func TestSequencedWithoutNonce(t *testing.T) {
+	c := New([]byte("secret"), 0)
+	server, client := session.New(nil), session.New(nil)
+	if _, err := c.SealSequenced(client, Upstream, []byte("hello")); err != ErrNoNonce {
+		t.Fatalf("expect %v, got %v", ErrNoNonce, err)
+	}
+
+	// the message sealed for a connection can not be replayed on the
+	// connection without nonce
+	client.SetNonce([]byte("connection-1"))
+	sealed, err := c.SealSequenced(client, Upstream, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OpenSequenced(server, Upstream, sealed); err != ErrNoNonce {
+		t.Fatalf("expect %v, got %v", ErrNoNonce, err)
+	}
+}
+
+func TestSequencedStages(t *testing.T) {
+	serverKey, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := serverKey.ServerCipher(clientKey.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := clientKey.ClientCipher(serverKey.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, client := session.New(nil), session.New(nil)
+	server.SetCipher(sc)
+	server.SetNonce([]byte("connection-1"))
+	client.SetCipher(cc)
+	client.SetNonce([]byte("connection-1"))
+
+	serverPipe, clientPipe := pipeline.New(), pipeline.New()
+	InstallSequencedStages(serverPipe, true)
+	InstallSequencedStages(clientPipe, false)
+	if serverPipe.Inbound().Requires() != pipeline.RequireCipher|pipeline.RequireNonce {
+		t.Fatalf("unexpected requirements: %v", serverPipe.Inbound().Requires())
+	}
+
+	plaintext := []byte("hello amoeba")
+	msg := &pipeline.Message{Data: plaintext}
+	if err := clientPipe.Outbound().Process(client, msg); err != nil {
+		t.Fatal(err)
+	}
+	sealed := msg.Data
+	msg = &pipeline.Message{Data: sealed}
+	if err := serverPipe.Inbound().Process(server, msg); err != nil || !bytes.Equal(msg.Data, plaintext) {
+		t.Fatalf("open sequenced failed: %v, %q", err, msg.Data)
+	}
+
+	// the payload sealed with the session cipher can not be replayed on the
+	// same connection
+	if err := serverPipe.Inbound().Process(server, &pipeline.Message{Data: sealed}); err != ErrReplayed {
+		t.Fatalf("expect %v, got %v", ErrReplayed, err)
+	}
+
+	// the session without cipher is rejected
+	if err := serverPipe.Inbound().Process(session.New(nil), &pipeline.Message{Data: sealed}); err != ErrNoSessionCipher {
+		t.Fatalf("expect %v, got %v", ErrNoSessionCipher, err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package errors provides the error which is sent to client as the response
+// of a failed request. The handlers could return an *Error with a custom code,
+// the other errors will be wrapped with CodeInternal, and only the generic
+// message of the code is sent for them.
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Error codes of the errors produced by framework, the custom codes should
+// avoid these values
+const (
+	CodeBadRequest  = 400 // the request payload could not be deserialized
+	CodeRejected    = 403 // the request was rejected by inbound pipeline
+	CodeNotFound    = 404 // no service provides the route
+	CodeRateLimited = 429 // the message exceeded the rate limit
+	CodeInternal    = 500 // the handler failed with an error which is not *Error
+	CodeUnavailable = 503 // the remote service could not be reached
+	CodeTimeout     = 504 // the deadline exceeded before the handler invoked
+)
+
+// Error is the envelope of error response, it is always encoded as JSON no
+// matter which serializer is used by the handler
+type Error struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// New returns an error with the code and message
+func New(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf returns an error with the code and formatted message
+func Errorf(code int, format string, args ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// messages are the generic messages of codes, which are sent to client for the
+// errors not created by this package
+var messages = map[int]string{
+	CodeBadRequest:  "bad request",
+	CodeRejected:    "request rejected",
+	CodeNotFound:    "route not found",
+	CodeRateLimited: "rate limit exceeded",
+	CodeInternal:    "internal error",
+	CodeUnavailable: "service unavailable",
+	CodeTimeout:     "deadline exceeded",
+}
+
+// Public returns the *Error in the chain of err like From, but the other
+// errors are replaced by the generic message of code, so the details of them,
+// e.g. the failures of database, are not exposed to clients
+func Public(err error, code int) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	msg, found := messages[code]
+	if !found {
+		msg = messages[CodeInternal]
+	}
+	return New(code, msg)
+}
+
+// From returns the *Error in the chain of err, or wraps err with code if
+// there is no *Error in the chain
+func From(err error, code int) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return New(code, err.Error())
+}
+
+// Encode returns the wire format of e
+func Encode(e *Error) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode parses the wire format of error, the data will be used as the
+// message if it is not a valid envelope
+func Decode(data []byte) *Error {
+	e := &Error{}
+	if err := json.Unmarshal(data, e); err != nil || e.Code == 0 {
+		return New(CodeInternal, string(data))
+	}
+	return e
+}
+
+// WithDetails returns a copy of e which carries the details
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Details: details}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+// Is reports whether target is an *Error with the same code, so the errors
+// decoded from response could be compared with the declared ones
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	e := New(1001, "insufficient gold")
+	if got := From(fmt.Errorf("buy: %w", e), CodeInternal); got != e {
+		t.Fatalf("expect %v, got %v", e, got)
+	}
+	if got := From(errors.New("boom"), CodeInternal); got.Code != CodeInternal || got.Message != "boom" {
+		t.Fatalf("unexpected error %v", got)
+	}
+}
+
+func TestPublic(t *testing.T) {
+	e := New(1001, "insufficient gold")
+	if got := Public(fmt.Errorf("buy: %w", e), CodeInternal); got != e {
+		t.Fatalf("expect %v, got %v", e, got)
+	}
+	if got := Public(errors.New("dial tcp 10.0.0.1:27017: connection refused"), CodeInternal); got.Code != CodeInternal || got.Message != "internal error" {
+		t.Fatalf("unexpected error %v", got)
+	}
+	if got := Public(errors.New("boom"), 599); got.Code != 599 || got.Message != "internal error" {
+		t.Fatalf("unexpected error %v", got)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	e := New(1001, "insufficient gold").WithDetails(map[string]interface{}{"need": "100"})
+	data, err := Encode(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Decode(data)
+	if d.Code != e.Code || d.Message != e.Message || d.Details["need"] != "100" {
+		t.Fatalf("expect %v, got %v", e, d)
+	}
+	if !errors.Is(d, New(1001, "")) {
+		t.Fatal("decoded error should match the code")
+	}
+	if d := Decode([]byte("oops")); d.Code != CodeInternal || d.Message != "oops" {
+		t.Fatalf("unexpected error %v", d)
+	}
+}
@@ -90,7 +90,7 @@ func InitAmoebaClient(addr string) {
 }
 
 func handleClose() {
-	sigChan := make(chan os.Signal)
+	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
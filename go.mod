@@ -4,9 +4,11 @@ go 1.16
 
 require (
 	firebase.google.com/go v3.13.0+incompatible
+	github.com/fxamacker/cbor/v2 v2.4.0
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/websocket v1.4.2
+	github.com/klauspost/compress v1.9.5
 	github.com/labstack/echo/v4 v4.6.0
 	github.com/pingcap/check v0.0.0-20200212061837-5e12011dc712
 	github.com/pingcap/errors v0.11.4
@@ -14,7 +16,9 @@ require (
 	github.com/revzim/azdrivers v0.0.11
 	github.com/revzim/go-pomelo-client v0.0.11
 	github.com/urfave/cli v1.22.5
+	github.com/vmihailenco/msgpack/v5 v5.3.5
 	go.mongodb.org/mongo-driver v1.7.2
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
 	google.golang.org/api v0.57.0
 	google.golang.org/grpc v1.40.0
 	google.golang.org/protobuf v1.27.1
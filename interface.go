@@ -21,6 +21,7 @@
 package amoeba
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -35,6 +36,7 @@ import (
 	"github.com/revzim/amoeba/internal/env"
 	"github.com/revzim/amoeba/internal/log"
 	"github.com/revzim/amoeba/internal/runtime"
+	"github.com/revzim/amoeba/ratelimit"
 	"github.com/revzim/amoeba/scheduler"
 )
 
@@ -79,7 +81,7 @@ func Listen(addr string, opts ...Option) {
 	}
 
 	// Use listen address as client address in non-cluster mode
-	if !opt.IsMaster && opt.AdvertiseAddr == "" && opt.ClientAddr == "" {
+	if !opt.IsMaster && opt.AdvertiseAddr == "" && len(opt.Masters) == 0 && opt.ClientAddr == "" {
 		log.Println("The current server running in singleton mode")
 		opt.ClientAddr = addr
 	}
@@ -108,7 +110,7 @@ func Listen(addr string, opts ...Option) {
 	}
 
 	go scheduler.Sched()
-	sg := make(chan os.Signal)
+	sg := make(chan os.Signal, 1)
 	signal.Notify(sg, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGKILL, syscall.SIGTERM)
 
 	select {
@@ -157,7 +159,7 @@ func ExtendEcho(e *echo.Echo, opts ...Option) {
 	}
 
 	// Use listen address as client address in non-cluster mode
-	if !opt.IsMaster && opt.AdvertiseAddr == "" && opt.ClientAddr == "" {
+	if !opt.IsMaster && opt.AdvertiseAddr == "" && len(opt.Masters) == 0 && opt.ClientAddr == "" {
 		log.Println("The current server running in singleton mode")
 		opt.ClientAddr = e.Server.Addr // addr
 	}
@@ -194,7 +196,7 @@ func ExtendEcho(e *echo.Echo, opts ...Option) {
 }
 
 func WatchShutdown() {
-	sg := make(chan os.Signal)
+	sg := make(chan os.Signal, 1)
 	signal.Notify(sg, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGKILL, syscall.SIGTERM)
 
 	select {
@@ -212,6 +214,36 @@ func WatchShutdown() {
 	atomic.StoreInt32(&running, 0)
 }
 
+// Call sends a request to the remote service which provides the route and waits
+// for the reply, it is only available in cluster mode after the node started
+func Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNodeNotRunning
+	}
+	return node.Call(ctx, route, v, reply)
+}
+
+// KickUID kicks all client sessions bound to the uid in cluster, the clients
+// will receive the reason before the connections closed
+func KickUID(uid int64, reason string) error {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNodeNotRunning
+	}
+	return node.KickUID(uid, reason)
+}
+
+// RateLimitViolations returns the counters of the client messages exceeded the
+// rate limits on current node
+func RateLimitViolations() (ratelimit.Violations, error) {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ratelimit.Violations{}, ErrNodeNotRunning
+	}
+	return node.RateLimitViolations(), nil
+}
+
 // Shutdown send a signal to let 'amoeba' shutdown itself.
 func Shutdown() {
 	close(env.Die)
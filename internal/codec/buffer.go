@@ -0,0 +1,80 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package codec
+
+import (
+	"sync"
+
+	"github.com/revzim/amoeba/internal/packet"
+)
+
+// the buffers larger than it will not be returned to pool, which prevents
+// the pool from holding the memory of occasional large packets
+const maxPooledBuffer = MaxPacketSize + HeadLength
+
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return &Buffer{B: make([]byte, 0, 1024)}
+	},
+}
+
+// Buffer is a pooled buffer which is used to build a packet without extra
+// allocations, the first HeadLength bytes are reserved for the packet header,
+// and the packet body should be appended to B.
+type Buffer struct {
+	B []byte
+}
+
+// NewBuffer returns a buffer from pool, which should be released by Free after
+// the packet has been written
+func NewBuffer() *Buffer {
+	b := buffers.Get().(*Buffer)
+	b.B = b.B[:HeadLength]
+	return b
+}
+
+// Free returns the buffer to pool, the buffer must not be used after Free. The
+// buffer which can not hold the header is dropped
+func (b *Buffer) Free() {
+	if c := cap(b.B); c > maxPooledBuffer || c < HeadLength {
+		return
+	}
+	buffers.Put(b)
+}
+
+// Frame writes the packet header into the reserved space and returns the
+// packet, the packet shares the memory of buffer if the body is not larger
+// than MaxPacketSize, otherwise the fragments will be returned in a new slice
+func Frame(typ packet.Type, b *Buffer) ([]byte, error) {
+	if typ < packet.Handshake || typ > packet.Fragment {
+		return nil, packet.ErrWrongPacketType
+	}
+	return frame(byte(typ), b), nil
+}
+
+func frame(typ byte, b *Buffer) []byte {
+	body := b.B[HeadLength:]
+	if len(body) > MaxPacketSize {
+		return encode(typ, body)
+	}
+	putHeader(b.B, typ, len(body))
+	return b.B
+}
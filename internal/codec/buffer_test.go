@@ -0,0 +1,137 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/revzim/amoeba/internal/message"
+	"github.com/revzim/amoeba/internal/packet"
+)
+
+func TestFrame(t *testing.T) {
+	for _, size := range []int{0, 100, MaxPacketSize, 2*MaxPacketSize + 1} {
+		data := bytes.Repeat([]byte{'a'}, size)
+		expect, err := Encode(packet.Data, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := NewBuffer()
+		b.B = append(b.B, data...)
+		p, err := Frame(packet.Data, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p, expect) {
+			t.Fatalf("unexpected packet of size %d", size)
+		}
+		b.Free()
+	}
+
+	if _, err := Frame(packet.Type(7), NewBuffer()); err != packet.ErrWrongPacketType {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFreeWithoutHeader(t *testing.T) {
+	// the buffer lost its memory is not pooled again
+	b := NewBuffer()
+	b.B = nil
+	b.Free()
+	for i := 0; i < 8; i++ {
+		if b := NewBuffer(); len(b.B) != HeadLength {
+			t.Fatalf("unexpected buffer length %d", len(b.B))
+		}
+	}
+}
+
+func TestDecoderOwnership(t *testing.T) {
+	pp, err := Encode(packet.Data, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDecoder()
+	buf := append([]byte(nil), pp...)
+	packets, err := d.Decode(buf)
+	if err != nil || len(packets) != 1 {
+		t.Fatalf("unexpected result %v %v", packets, err)
+	}
+	// neither reusing the read buffer nor decoding more packets changes the
+	// data of decoded packets
+	copy(buf, bytes.Repeat([]byte{0x04}, len(buf)))
+	for i := 0; i < 16; i++ {
+		if _, err := d.Decode(pp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(packets[0].Data) != "hello" {
+		t.Fatalf("packet data overwritten: %q", packets[0].Data)
+	}
+}
+
+func benchmarkMessage() *message.Message {
+	return &message.Message{
+		Type:  message.Push,
+		Route: "room.message",
+		Data:  bytes.Repeat([]byte{'a'}, 256),
+	}
+}
+
+// BenchmarkEncodeMessage encodes the message and packet in separated buffers
+func BenchmarkEncodeMessage(b *testing.B) {
+	m := benchmarkMessage()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		em, err := m.Encode()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Encode(packet.Data, em); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFrameMessage builds the message and packet in a pooled buffer
+func BenchmarkFrameMessage(b *testing.B) {
+	m := benchmarkMessage()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf := NewBuffer()
+			var err error
+			if buf.B, err = message.Append(buf.B, m); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := Frame(packet.Data, buf); err != nil {
+				b.Fatal(err)
+			}
+			buf.Free()
+		}
+	})
+}
+
+// BenchmarkDecoder_DecodeBatch decodes the packets received in one read
+func BenchmarkDecoder_DecodeBatch(b *testing.B) {
+	var data []byte
+	for i := 0; i < 8; i++ {
+		pp, err := Encode(packet.Data, []byte("hello world"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		data = append(data, pp...)
+	}
+
+	b.ReportAllocs()
+	d := NewDecoder()
+	for i := 0; i < b.N; i++ {
+		packets, err := d.Decode(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(packets) != 8 {
+			b.Fatal("decode error")
+		}
+	}
+}
@@ -23,6 +23,7 @@ package codec
 import (
 	"bytes"
 	"errors"
+	"time"
 
 	"github.com/revzim/amoeba/internal/packet"
 )
@@ -33,76 +34,165 @@ const (
 	MaxPacketSize = 64 * 1024
 )
 
-// ErrPacketSizeExcced is the error used for encode/decode.
-var ErrPacketSizeExcced = errors.New("codec: packet size exceed")
+// fragment header: 1 byte type of the original packet and 1 byte flags
+const (
+	fragmentHeadLength = 2
+	fragmentLast       = 0x01
+)
+
+// The packets larger than MaxPacketSize will be split into fragments, and
+// the fragments will be reassembled by the decoder of other side, the
+// reassembly memory of each connection is limited by the MaxReassemblySize of
+// decoder and the fragments of a packet should be received within the
+// ReassemblyTimeout, see Decoder.Deadline.
+const (
+	DefaultMaxReassemblySize = 4 * 1024 * 1024
+	DefaultReassemblyTimeout = 30 * time.Second
+)
+
+// Errors that could be occurred in encode/decode
+var (
+	ErrPacketSizeExcced     = errors.New("codec: packet size exceed")
+	ErrReassemblySizeExceed = errors.New("codec: reassembly size exceed")
+	ErrReassemblyTimeout    = errors.New("codec: reassembly timeout")
+	ErrInvalidFragment      = errors.New("codec: invalid fragment")
+)
 
 // A Decoder reads and decodes network data slice
 type Decoder struct {
-	buf  *bytes.Buffer
-	size int  // last packet length
-	typ  byte // last packet type
+	MaxReassemblySize int           // max reassembly memory
+	ReassemblyTimeout time.Duration // max time to receive the fragments of a packet
+
+	buf *bytes.Buffer
+
+	fragments []byte    // reassembling packet body
+	fragType  byte      // type of reassembling packet
+	fragStart time.Time // time of the first fragment received
 }
 
 // NewDecoder returns a new decoder that used for decode network bytes slice.
 func NewDecoder() *Decoder {
 	return &Decoder{
-		buf:  bytes.NewBuffer(nil),
-		size: -1,
+		MaxReassemblySize: DefaultMaxReassemblySize,
+		ReassemblyTimeout: DefaultReassemblyTimeout,
+		buf:               bytes.NewBuffer(nil),
 	}
 }
 
-func (c *Decoder) forward() error {
-	header := c.buf.Next(HeadLength)
-	c.typ = header[0]
-	if c.typ < packet.Handshake || c.typ > packet.Kick {
-		return packet.ErrWrongPacketType
+// Deadline returns the time by which the reassembling packet must be completed,
+// the zero time if no packet is being reassembled. The reader should set it as
+// the read deadline of connection, so the peer which stops sending fragments
+// will not pin the reassembly memory forever
+func (c *Decoder) Deadline() time.Time {
+	if c.fragments == nil {
+		return time.Time{}
 	}
-	c.size = bytesToInt(header[1:])
+	return c.fragStart.Add(c.ReassemblyTimeout)
+}
 
-	// packet length limitation
-	if c.size > MaxPacketSize {
-		return ErrPacketSizeExcced
+// complete returns the length and count of the complete packets at the head of
+// buffer, and the error of the first invalid packet header
+func (c *Decoder) complete() (int, int, error) {
+	b := c.buf.Bytes()
+	n, count := 0, 0
+	for len(b)-n >= HeadLength {
+		typ := b[n] &^ packet.Compressed
+		if typ < packet.Handshake || typ > packet.Fragment {
+			return n, count, packet.ErrWrongPacketType
+		}
+		// packet length limitation
+		size := bytesToInt(b[n+1 : n+HeadLength])
+		if size > MaxPacketSize {
+			return n, count, ErrPacketSizeExcced
+		}
+		if len(b)-n-HeadLength < size {
+			break
+		}
+		n += HeadLength + size
+		count++
 	}
-	return nil
+	return n, count, nil
 }
 
-// Decode decode the network bytes slice to packet.Packet(s)
-// TODO(Warning): shared slice
+// Decode decode the network bytes slice to packet.Packet(s), the data slice
+// could be reused by caller after Decode returned. The complete packets are
+// copied out of the decoder buffer at once, so the data of packets returned
+// are owned by caller and will not be overwritten by the next Decode.
 func (c *Decoder) Decode(data []byte) ([]*packet.Packet, error) {
 	c.buf.Write(data)
 
+	n, count, invalid := c.complete()
+	if count == 0 {
+		return nil, invalid
+	}
+	buf := make([]byte, n)
+	copy(buf, c.buf.Next(n))
+
 	var (
-		packets []*packet.Packet
+		packets = make([]*packet.Packet, 0, count)
+		values  = make([]packet.Packet, count)
 		err     error
 	)
-	// check length
-	if c.buf.Len() < HeadLength {
-		return nil, err
-	}
+	for i, offset := 0, 0; offset < n; i++ {
+		typ := buf[offset]
+		size := bytesToInt(buf[offset+1 : offset+HeadLength])
+		offset += HeadLength
+		p := &values[i]
+		*p = packet.Packet{
+			Type:       packet.Type(typ &^ packet.Compressed),
+			Length:     size,
+			Data:       buf[offset : offset+size : offset+size],
+			Compressed: typ&packet.Compressed != 0,
+		}
+		offset += size
 
-	// first time
-	if c.size < 0 {
-		if err = c.forward(); err != nil {
-			return nil, err
+		if p.Type == packet.Fragment {
+			if p, err = c.reassemble(p); err != nil {
+				return packets, err
+			}
+		}
+		if p != nil {
+			packets = append(packets, p)
 		}
 	}
 
-	for c.size <= c.buf.Len() {
-		p := &packet.Packet{Type: packet.Type(c.typ), Length: c.size, Data: c.buf.Next(c.size)}
-		packets = append(packets, p)
+	return packets, invalid
+}
 
-		// more packet
-		if c.buf.Len() < HeadLength {
-			c.size = -1
-			break
-		}
+// reassemble appends the fragment to the reassembling packet, the packet will
+// be returned after the last fragment received
+func (c *Decoder) reassemble(p *packet.Packet) (*packet.Packet, error) {
+	if len(p.Data) < fragmentHeadLength {
+		return nil, ErrInvalidFragment
+	}
+	typ, flags := p.Data[0], p.Data[1]
+	if c.fragments == nil {
+		c.fragType = typ
+		c.fragStart = time.Now()
+	} else if typ != c.fragType {
+		return nil, ErrInvalidFragment
+	}
+	if time.Since(c.fragStart) > c.ReassemblyTimeout {
+		return nil, ErrReassemblyTimeout
+	}
 
-		if err = c.forward(); err != nil {
-			return packets, err
-		}
+	chunk := p.Data[fragmentHeadLength:]
+	if len(c.fragments)+len(chunk) > c.MaxReassemblySize {
+		return nil, ErrReassemblySizeExceed
+	}
+	c.fragments = append(c.fragments, chunk...)
+	if flags&fragmentLast == 0 {
+		return nil, nil
 	}
 
-	return packets, nil
+	data := c.fragments
+	c.fragments = nil
+	return &packet.Packet{
+		Type:       packet.Type(typ &^ packet.Compressed),
+		Length:     len(data),
+		Data:       data,
+		Compressed: typ&packet.Compressed != 0,
+	}, nil
 }
 
 // Encode create a packet.Packet from  the raw bytes slice and then encode to network bytes slice
@@ -111,19 +201,45 @@ func (c *Decoder) Decode(data []byte) ([]*packet.Packet, error) {
 // -<type>-|--------<length>--------|-<data>-
 // --------|------------------------|--------
 // 1 byte packet type, 3 bytes packet data length(big end), and data segment
+//
+// The data larger than MaxPacketSize will be split into fragment packets:
+//
+// -<type>-|--------<length>--------|-<original type>-|-<flags>-|-<chunk>-
+// --------|------------------------|-----------------|---------|---------
+// 0x06 fragment type, 1 byte type of original packet, 1 byte flags which
+// the lowest bit marks the last fragment, and chunk of original data
 func Encode(typ packet.Type, data []byte) ([]byte, error) {
-	if typ < packet.Handshake || typ > packet.Kick {
+	if typ < packet.Handshake || typ > packet.Fragment {
 		return nil, packet.ErrWrongPacketType
 	}
+	return encode(byte(typ), data), nil
+}
 
-	p := &packet.Packet{Type: typ, Length: len(data)}
-	buf := make([]byte, p.Length+HeadLength)
-	buf[0] = byte(p.Type)
-
-	copy(buf[1:HeadLength], intToBytes(p.Length))
-	copy(buf[HeadLength:], data)
+// encode encodes the data with the raw type byte, which may contain flags
+func encode(typ byte, data []byte) []byte {
+	if len(data) <= MaxPacketSize {
+		buf := make([]byte, len(data)+HeadLength)
+		putHeader(buf, typ, len(data))
+		copy(buf[HeadLength:], data)
+		return buf
+	}
 
-	return buf, nil
+	const chunkSize = MaxPacketSize - fragmentHeadLength
+	count := (len(data) + chunkSize - 1) / chunkSize
+	buf := make([]byte, 0, len(data)+count*(HeadLength+fragmentHeadLength))
+	for offset := 0; offset < len(data); offset += chunkSize {
+		end := offset + chunkSize
+		flags := byte(0)
+		if end >= len(data) {
+			end = len(data)
+			flags = fragmentLast
+		}
+		n := end - offset + fragmentHeadLength
+		buf = append(buf, packet.Fragment, byte(n>>16), byte(n>>8), byte(n))
+		buf = append(buf, typ, flags)
+		buf = append(buf, data[offset:end]...)
+	}
+	return buf
 }
 
 // Decode packet data length byte to int(Big end)
@@ -135,11 +251,11 @@ func bytesToInt(b []byte) int {
 	return result
 }
 
-// Encode packet data length to bytes(Big end)
-func intToBytes(n int) []byte {
-	buf := make([]byte, 3)
-	buf[0] = byte((n >> 16) & 0xFF)
-	buf[1] = byte((n >> 8) & 0xFF)
-	buf[2] = byte(n & 0xFF)
-	return buf
+// putHeader writes the packet type and the data length(Big end) into the
+// first HeadLength bytes of buf
+func putHeader(buf []byte, typ byte, n int) {
+	buf[0] = typ
+	buf[1] = byte((n >> 16) & 0xFF)
+	buf[2] = byte((n >> 8) & 0xFF)
+	buf[3] = byte(n & 0xFF)
 }
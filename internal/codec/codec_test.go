@@ -50,8 +50,8 @@ func TestPack(t *testing.T) {
 		t.Error("should err")
 	}
 
-	_ = &Packet{Type: Type(6), Data: data, Length: len(data)}
-	if _, err = Encode(Type(6), data); err == nil {
+	_ = &Packet{Type: Type(7), Data: data, Length: len(data)}
+	if _, err = Encode(Type(7), data); err == nil {
 		t.Error("should err")
 	}
 
@@ -93,3 +93,67 @@ func BenchmarkDecoder_Decode(b *testing.B) {
 		}
 	}
 }
+
+func TestFragment(t *testing.T) {
+	data := make([]byte, 3*MaxPacketSize+100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	pp, err := Encode(Data, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Type(pp[0]) != Fragment {
+		t.Fatalf("expect fragment, got: %v", pp[0])
+	}
+
+	// feed the decoder in small pieces with a heartbeat after the fragments
+	hb, _ := Encode(Heartbeat, nil)
+	pp = append(pp, hb...)
+	d := NewDecoder()
+	var packets []*Packet
+	for i := 0; i < len(pp); i += 1000 {
+		end := i + 1000
+		if end > len(pp) {
+			end = len(pp)
+		}
+		ps, err := d.Decode(pp[i:end])
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, ps...)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("expect 2 packets, got: %d", len(packets))
+	}
+	expect := &Packet{Type: Data, Length: len(data), Data: data}
+	if !reflect.DeepEqual(expect, packets[0]) {
+		t.Fatal("reassembled packet not equal")
+	}
+	if packets[1].Type != Heartbeat {
+		t.Fatalf("expect heartbeat, got: %v", packets[1])
+	}
+
+	// reassembly memory limitation
+	d = NewDecoder()
+	d.MaxReassemblySize = 2 * MaxPacketSize
+	if _, err := d.Decode(pp); err != ErrReassemblySizeExceed {
+		t.Fatalf("expect %v, got: %v", ErrReassemblySizeExceed, err)
+	}
+
+	// reassembly timeout
+	d = NewDecoder()
+	if !d.Deadline().IsZero() {
+		t.Fatal("expect zero deadline without reassembling packet")
+	}
+	if _, err := d.Decode(pp[:MaxPacketSize+HeadLength]); err != nil {
+		t.Fatal(err)
+	}
+	if d.Deadline().IsZero() {
+		t.Fatal("expect deadline while reassembling packet")
+	}
+	d.fragStart = d.fragStart.Add(-2 * d.ReassemblyTimeout)
+	if _, err := d.Decode(pp[MaxPacketSize+HeadLength:]); err != ErrReassemblyTimeout {
+		t.Fatalf("expect %v, got: %v", ErrReassemblyTimeout, err)
+	}
+}
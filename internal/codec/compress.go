@@ -0,0 +1,236 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/revzim/amoeba/internal/packet"
+)
+
+// Compression algorithms
+const (
+	Deflate = "deflate"
+	Zstd    = "zstd"
+)
+
+// Errors that could be occurred in compression
+var (
+	ErrUnknownCompression = errors.New("codec: unknown compression algorithm")
+	ErrUnexpectedCompress = errors.New("codec: compressed packet without negotiated compression")
+	ErrDecompressExceed   = errors.New("codec: decompressed size exceed")
+	ErrDictUnsupported    = errors.New("codec: shared dictionary is only supported by deflate")
+)
+
+var (
+	zstdOnce     sync.Once
+	zstdEncoder  *zstd.Encoder
+	zstdErr      error
+	zstdDecoders sync.Map // max decompressed size => *zstd.Decoder
+)
+
+// zstdDecoder returns the shared decoder which limits the decompressed size
+func zstdDecoder(limit int) (*zstd.Decoder, error) {
+	if d, found := zstdDecoders.Load(limit); found {
+		return d.(*zstd.Decoder), nil
+	}
+	d, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := zstdDecoders.LoadOrStore(limit, d)
+	if loaded {
+		d.Close()
+	}
+	return actual.(*zstd.Decoder), nil
+}
+
+// DictChecksum returns the checksum of compression dictionary, which is used
+// to verify that both ends share the same dictionary
+func DictChecksum(dict []byte) string {
+	if len(dict) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE(dict))
+}
+
+// flateReader is the reader returned by flate.NewReaderDict which could be reset
+type flateReader interface {
+	io.ReadCloser
+	flate.Resetter
+}
+
+// Compression compresses the packet bodies of a connection, the bodies which
+// shorter than threshold will be sent uncompressed. The shared dictionary is
+// only supported by deflate, zstd with a dictionary is rejected. The
+// decompressed size is limited by the limit, which is usually the max
+// reassembly size of decoder.
+type Compression struct {
+	algo      string
+	threshold int
+	dict      []byte
+	limit     int       // max decompressed size
+	writers   sync.Pool // deflate writers
+	readers   sync.Pool // deflate readers
+}
+
+// NewCompression returns the compression of algorithm, limit is the max size
+// of decompressed packet body
+func NewCompression(algo string, threshold int, dict []byte, limit int) (*Compression, error) {
+	c := &Compression{algo: algo, threshold: threshold, limit: limit}
+	switch algo {
+	case Deflate:
+		c.dict = dict
+	case Zstd:
+		if len(dict) > 0 {
+			return nil, ErrDictUnsupported
+		}
+		zstdOnce.Do(func() {
+			zstdEncoder, zstdErr = zstd.NewWriter(nil)
+		})
+		if zstdErr != nil {
+			return nil, zstdErr
+		}
+		if _, err := zstdDecoder(limit); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownCompression
+	}
+	return c, nil
+}
+
+// Algorithm returns the name of compression algorithm
+func (c *Compression) Algorithm() string {
+	return c.algo
+}
+
+// Encode creates a packet like Encode, the body will be compressed and the
+// packet type will be marked with packet.Compressed if it is long enough
+func (c *Compression) Encode(typ packet.Type, data []byte) ([]byte, error) {
+	if c == nil || len(data) < c.threshold {
+		return Encode(typ, data)
+	}
+
+	compressed, err := c.compress(data)
+	if err != nil {
+		return nil, err
+	}
+	// compression does not always make things smaller
+	if len(compressed) >= len(data) {
+		return Encode(typ, data)
+	}
+	if typ < packet.Handshake || typ > packet.Fragment {
+		return nil, packet.ErrWrongPacketType
+	}
+	return encode(byte(typ)|packet.Compressed, compressed), nil
+}
+
+// Frame is similar to the Frame function, the body in buffer will be
+// compressed if it is long enough
+func (c *Compression) Frame(typ packet.Type, b *Buffer) ([]byte, error) {
+	body := b.B[HeadLength:]
+	if c == nil || len(body) < c.threshold {
+		return Frame(typ, b)
+	}
+	return c.Encode(typ, body)
+}
+
+// Decompress decompresses the body of packet if it is compressed
+func (c *Compression) Decompress(p *packet.Packet) error {
+	if !p.Compressed {
+		return nil
+	}
+	if c == nil {
+		return ErrUnexpectedCompress
+	}
+
+	data, err := c.decompress(p.Data)
+	if err != nil {
+		return err
+	}
+	p.Data = data
+	p.Length = len(data)
+	p.Compressed = false
+	return nil
+}
+
+func (c *Compression) compress(data []byte) ([]byte, error) {
+	if c.algo == Zstd {
+		return zstdEncoder.EncodeAll(data, nil), nil
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	w, ok := c.writers.Get().(*flate.Writer)
+	if !ok {
+		var err error
+		if w, err = flate.NewWriterDict(nil, flate.DefaultCompression, c.dict); err != nil {
+			return nil, err
+		}
+	}
+	defer c.writers.Put(w)
+
+	w.Reset(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Compression) decompress(data []byte) ([]byte, error) {
+	if c.algo == Zstd {
+		d, err := zstdDecoder(c.limit)
+		if err != nil {
+			return nil, err
+		}
+		return d.DecodeAll(data, nil)
+	}
+
+	r, ok := c.readers.Get().(flateReader)
+	if ok {
+		if err := r.Reset(bytes.NewReader(data), c.dict); err != nil {
+			return nil, err
+		}
+	} else {
+		r = flate.NewReaderDict(bytes.NewReader(data), c.dict).(flateReader)
+	}
+	defer c.readers.Put(r)
+
+	result, err := ioutil.ReadAll(io.LimitReader(r, int64(c.limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > c.limit {
+		return nil, ErrDecompressExceed
+	}
+	return result, nil
+}
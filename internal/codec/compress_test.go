@@ -0,0 +1,81 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/revzim/amoeba/internal/packet"
+)
+
+func TestCompression(t *testing.T) {
+	snapshot := bytes.Repeat([]byte(`{"id":1,"x":100,"y":200},`), 100)
+	dict := []byte(`{"id":1,"x":100,"y":200}`)
+
+	for _, algo := range []string{Deflate, Zstd} {
+		// the shared dictionary is only supported by deflate
+		dict := dict
+		if algo == Zstd {
+			dict = nil
+		}
+		c, err := NewCompression(algo, 64, dict, DefaultMaxReassemblySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		small, err := c.Encode(Data, []byte("hello world"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		large, err := c.Encode(Data, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(large) >= len(snapshot) {
+			t.Fatalf("%s: packet not compressed", algo)
+		}
+
+		packets, err := NewDecoder().Decode(append(small, large...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packets) != 2 || packets[0].Compressed || !packets[1].Compressed {
+			t.Fatalf("%s: unexpected packets %v", algo, packets)
+		}
+		for _, p := range packets {
+			if err := c.Decompress(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if string(packets[0].Data) != "hello world" || !bytes.Equal(packets[1].Data, snapshot) {
+			t.Fatalf("%s: unexpected packet data", algo)
+		}
+		if packets[1].Type != Data || packets[1].Length != len(snapshot) {
+			t.Fatalf("%s: unexpected packet %v", algo, packets[1])
+		}
+
+		// decompressed size limitation
+		limited, err := NewCompression(algo, 64, dict, len(snapshot)/2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets, err = NewDecoder().Decode(large)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := limited.Decompress(packets[0]); err == nil {
+			t.Fatalf("%s: expect decompressed size exceed", algo)
+		}
+	}
+
+	var none *Compression
+	p := &Packet{Type: Data, Compressed: true}
+	if err := none.Decompress(p); err != ErrUnexpectedCompress {
+		t.Fatal(err)
+	}
+	if _, err := NewCompression("gzip", 0, nil, DefaultMaxReassemblySize); err != ErrUnknownCompression {
+		t.Fatal(err)
+	}
+	if _, err := NewCompression(Zstd, 0, dict, DefaultMaxReassemblySize); err != ErrDictUnsupported {
+		t.Fatal(err)
+	}
+}
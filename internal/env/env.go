@@ -41,6 +41,7 @@ var (
 	Debug              bool                     // enable Debug
 	WSPath             string                   // WebSocket path(eg: ws://127.0.0.1/WSPath)
 	HandshakeValidator func([]byte) error       // When you need to verify the custom data of the handshake request
+	CallTimeout        time.Duration            // default deadline of remote call which context has no deadline
 
 	// timerPrecision indicates the precision of timer, default is time.Second
 	TimerPrecision = time.Second
@@ -63,6 +64,7 @@ var (
 func init() {
 	Die = make(chan bool)
 	Heartbeat = 30 * time.Second
+	CallTimeout = 5 * time.Second
 	Debug = false
 	CheckOrigin = func(_ *http.Request) bool { return true }
 	HandshakeValidator = func(_ []byte) error { return nil }
@@ -21,10 +21,14 @@
 package message
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/revzim/amoeba/internal/log"
 )
@@ -42,6 +46,7 @@ const (
 
 const (
 	msgRouteCompressMask = 0x01
+	msgErrorMask         = 0x20
 	msgTypeMask          = 0x07
 	msgRouteLengthMask   = 0xFF
 	msgHeadLength        = 0x02
@@ -58,11 +63,21 @@ func (t Type) String() string {
 	return types[t]
 }
 
+// global is the *Dictionary set by SetDictionary, it is replaced rather than
+// modified, so the routes could be added while encoding and decoding
 var (
-	routes = make(map[string]uint16) // route map to code
-	codes  = make(map[uint16]string) // code map to route
+	global   atomic.Value
+	muGlobal sync.Mutex // serializes the updates of global
 )
 
+func init() {
+	global.Store(NewDictionary(nil))
+}
+
+func current() *Dictionary {
+	return global.Load().(*Dictionary)
+}
+
 // Errors that could be occurred in message codec
 var (
 	ErrWrongMessageType  = errors.New("wrong message type")
@@ -77,6 +92,7 @@ type Message struct {
 	ID         uint64 // unique id, zero while notify mode
 	Route      string // route for locating service
 	Data       []byte // payload
+	Error      bool   // response carries an error envelope instead of payload
 	compressed bool   // is message compressed
 }
 
@@ -116,14 +132,53 @@ func invalidType(t Type) bool {
 // | push     |----011-|<route>             |
 // ------------------------------------------
 // The figure above indicates that the bit does not affect the type of message.
+// The 6th bit(0x20) of flag marks the response of a failed request, the data of
+// which is an error envelope, see the errors package.
 // See ref: https://github.com/lonnng/amoeba/blob/master/docs/communication_protocol.md
 func Encode(m *Message) ([]byte, error) {
+	return current().Encode(m)
+}
+
+// Append appends the binary format of message to buf and returns the extended
+// buffer, which is used to build the message in a reusable buffer
+func Append(buf []byte, m *Message) ([]byte, error) {
+	return current().Append(buf, m)
+}
+
+func encode(m *Message, routes map[string]uint16) ([]byte, error) {
+	return appendMessage(make([]byte, 0, encodedLength(m, routes)), m, routes)
+}
+
+// encodedLength returns the length of the binary format of message
+func encodedLength(m *Message, routes map[string]uint16) int {
+	n := 1 + len(m.Data)
+	if m.Type == Request || m.Type == Response {
+		for id := m.ID; ; id >>= 7 {
+			n++
+			if id < 128 {
+				break
+			}
+		}
+	}
+	if routable(m.Type) {
+		if _, compressed := routes[m.Route]; compressed {
+			n += 2
+		} else {
+			n += 1 + len(m.Route)
+		}
+	}
+	return n
+}
+
+func appendMessage(buf []byte, m *Message, routes map[string]uint16) ([]byte, error) {
 	if invalidType(m.Type) {
 		return nil, ErrWrongMessageType
 	}
 
-	buf := make([]byte, 0)
 	flag := byte(m.Type) << 1
+	if m.Error {
+		flag |= msgErrorMask
+	}
 
 	code, compressed := routes[m.Route]
 	if compressed {
@@ -152,7 +207,7 @@ func Encode(m *Message) ([]byte, error) {
 			buf = append(buf, byte(code&0xFF))
 		} else {
 			buf = append(buf, byte(len(m.Route)))
-			buf = append(buf, []byte(m.Route)...)
+			buf = append(buf, m.Route...)
 		}
 	}
 
@@ -163,6 +218,10 @@ func Encode(m *Message) ([]byte, error) {
 // Decode unmarshal the bytes slice to a message
 // See ref: https://github.com/lonnng/amoeba/blob/master/docs/communication_protocol.md
 func Decode(data []byte) (*Message, error) {
+	return current().Decode(data)
+}
+
+func decode(data []byte, codes map[uint16]string) (*Message, error) {
 	if len(data) < msgHeadLength {
 		return nil, ErrInvalidMessage
 	}
@@ -170,6 +229,7 @@ func Decode(data []byte) (*Message, error) {
 	flag := data[0]
 	offset := 1
 	m.Type = Type((flag >> 1) & msgTypeMask)
+	m.Error = flag&msgErrorMask != 0
 
 	if invalidType(m.Type) {
 		return nil, ErrWrongMessageType
@@ -224,9 +284,22 @@ func Decode(data []byte) (*Message, error) {
 	return m, nil
 }
 
-// SetDictionary set routes map which be used to compress route.
-// TODO(warning): set dictionary in runtime would be a dangerous operation!!!!!!
+// SetDictionary set routes map which be used to compress route, the routes are
+// merged into the existing ones. It is safe to set the dictionary in runtime,
+// the messages being encoded or decoded use the previous one
 func SetDictionary(dict map[string]uint16) {
+	muGlobal.Lock()
+	defer muGlobal.Unlock()
+
+	old := current()
+	routes := make(map[string]uint16, len(old.routes)+len(dict))
+	codes := make(map[uint16]string, len(old.codes)+len(dict))
+	for r, code := range old.routes {
+		routes[r] = code
+	}
+	for code, r := range old.codes {
+		codes[code] = r
+	}
 	for route, code := range dict {
 		r := strings.TrimSpace(route)
 
@@ -243,4 +316,71 @@ func SetDictionary(dict map[string]uint16) {
 		routes[r] = code
 		codes[code] = r
 	}
+	global.Store(&Dictionary{routes: routes, codes: codes})
+}
+
+// Routes returns a copy of the routes map set by SetDictionary
+func Routes() map[string]uint16 {
+	return current().Routes()
+}
+
+// DictionaryHash returns the digest of routes map, the peers could compare the
+// hash to find out whether they hold the same dictionary
+func DictionaryHash(dict map[string]uint16) string {
+	keys := make([]string, 0, len(dict))
+	for route := range dict {
+		keys = append(keys, strings.TrimSpace(route))
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, route := range keys {
+		fmt.Fprintf(h, "%s=%d;", route, dict[route])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+}
+
+// Dictionary is a routes map which is independent of the global one, it is
+// used by the peers which receive the dictionary from remote, e.g. client
+type Dictionary struct {
+	routes map[string]uint16 // route map to code
+	codes  map[uint16]string // code map to route
+}
+
+// NewDictionary returns a dictionary contains the routes map
+func NewDictionary(dict map[string]uint16) *Dictionary {
+	d := &Dictionary{
+		routes: make(map[string]uint16, len(dict)),
+		codes:  make(map[uint16]string, len(dict)),
+	}
+	for route, code := range dict {
+		r := strings.TrimSpace(route)
+		d.routes[r] = code
+		d.codes[code] = r
+	}
+	return d
+}
+
+// Encode marshals message to binary format with the routes of dictionary
+func (d *Dictionary) Encode(m *Message) ([]byte, error) {
+	return encode(m, d.routes)
+}
+
+// Append appends the binary format of message to buf with the routes of dictionary
+func (d *Dictionary) Append(buf []byte, m *Message) ([]byte, error) {
+	return appendMessage(buf, m, d.routes)
+}
+
+// Decode unmarshal the bytes slice to a message with the routes of dictionary
+func (d *Dictionary) Decode(data []byte) (*Message, error) {
+	return decode(data, d.codes)
+}
+
+// Routes returns a copy of the routes map of dictionary
+func (d *Dictionary) Routes() map[string]uint16 {
+	dict := make(map[string]uint16, len(d.routes))
+	for route, code := range d.routes {
+		dict[route] = code
+	}
+	return dict
 }
@@ -161,4 +161,62 @@ func TestEncode(t *testing.T) {
 	if !reflect.DeepEqual(m8, dm8) {
 		t.Error("not equal")
 	}
+
+	m9 := &Message{
+		Type:  Response,
+		ID:    129,
+		Data:  []byte(`{"code":404,"message":"not found"}`),
+		Error: true,
+	}
+	em9, err := m9.Encode()
+	if err != nil {
+		t.Error(err.Error())
+	}
+	dm9, err := Decode(em9)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(m9, dm9) {
+		t.Error("not equal")
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	d := NewDictionary(map[string]uint16{"room.join": 1})
+	m := &Message{
+		Type:       Push,
+		Route:      "room.join",
+		Data:       []byte(`hello world`),
+		compressed: true,
+	}
+	em, err := d.Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if em[0]&msgRouteCompressMask == 0 {
+		t.Fatal("route not compressed")
+	}
+	dm, err := d.Decode(em)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, dm) {
+		t.Error("not equal")
+	}
+	if _, err := NewDictionary(nil).Decode(em); err != ErrRouteInfoNotFound {
+		t.Error(err)
+	}
+}
+
+func TestDictionaryHash(t *testing.T) {
+	a := map[string]uint16{"room.join": 1, "room.leave": 2}
+	b := NewDictionary(map[string]uint16{" room.leave": 2, "room.join": 1}).Routes()
+	if DictionaryHash(a) != DictionaryHash(b) {
+		t.Fatal("hash of same routes not equal")
+	}
+	b["room.leave"] = 3
+	if DictionaryHash(a) == DictionaryHash(b) {
+		t.Fatal("hash of different routes equal")
+	}
 }
@@ -20,15 +20,49 @@
 
 package message
 
-import "github.com/revzim/amoeba/internal/env"
+import (
+	"github.com/revzim/amoeba/internal/env"
+	"github.com/revzim/amoeba/serialize"
+)
 
 func Serialize(v interface{}) ([]byte, error) {
+	return SerializeWith(nil, v)
+}
+
+// SerializeWith is similar to Serialize, but marshals v with the serializer,
+// the default serializer will be used if it is nil
+func SerializeWith(s serialize.Serializer, v interface{}) ([]byte, error) {
 	if data, ok := v.([]byte); ok {
 		return data, nil
 	}
-	data, err := env.Serializer.Marshal(v)
+	if s == nil {
+		s = env.Serializer
+	}
+	data, err := s.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
+
+// Deserialize unmarshals data into v, the raw data will be stored if v is
+// a pointer to byte slice
+func Deserialize(data []byte, v interface{}) error {
+	return DeserializeWith(nil, data, v)
+}
+
+// DeserializeWith is similar to Deserialize, but unmarshals data with the
+// serializer, the default serializer will be used if it is nil
+func DeserializeWith(s serialize.Serializer, data []byte, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	if raw, ok := v.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	if s == nil {
+		s = env.Serializer
+	}
+	return s.Unmarshal(data, v)
+}
@@ -44,16 +44,24 @@ const (
 
 	// Kick represents a kick off packet
 	Kick = 0x05 // disconnect message from server
+
+	// Fragment represents a chunk of packet which is larger than the max packet size
+	Fragment = 0x06
 )
 
+// Compressed is the flag in the highest bit of packet type which marks the
+// packet body is compressed
+const Compressed = 0x80
+
 // ErrWrongPacketType represents a wrong packet type.
 var ErrWrongPacketType = errors.New("wrong packet type")
 
 // Packet represents a network packet.
 type Packet struct {
-	Type   Type
-	Length int
-	Data   []byte
+	Type       Type
+	Length     int
+	Data       []byte
+	Compressed bool // the body is compressed
 }
 
 //New create a Packet instance.
@@ -21,6 +21,7 @@
 package mock
 
 import (
+	"context"
 	"fmt"
 	"net"
 )
@@ -46,6 +47,7 @@ type NetworkEntity struct {
 	responses []interface{}
 	msgmap    map[uint64]interface{}
 	rpcCall   []message
+	kicked    []string
 }
 
 // NewNetworkEntity returns an mock network entity
@@ -61,6 +63,12 @@ func (n *NetworkEntity) RPC(route string, v interface{}) error {
 	return nil
 }
 
+// Call implements the session.NetworkEntity interface
+func (n *NetworkEntity) Call(_ context.Context, route string, v interface{}, _ interface{}) error {
+	n.rpcCall = append(n.rpcCall, message{route: route, data: v})
+	return nil
+}
+
 // Push implements the session.NetworkEntity interface
 func (n *NetworkEntity) Push(route string, v interface{}) error {
 	n.messages = append(n.messages, message{route: route, data: v})
@@ -93,6 +101,12 @@ func (n *NetworkEntity) Close() error {
 	return nil
 }
 
+// Kick implements the session.NetworkEntity interface
+func (n *NetworkEntity) Kick(reason string) error {
+	n.kicked = append(n.kicked, reason)
+	return nil
+}
+
 // RemoteAddr implements the session.NetworkEntity interface
 func (n *NetworkEntity) RemoteAddr() net.Addr {
 	return NetAddr{}
@@ -120,3 +134,11 @@ func (n *NetworkEntity) FindResponseByRoute(route string) interface{} {
 	}
 	return nil
 }
+
+// LastKick returns the reason of last kick, empty if the entity is not kicked
+func (n *NetworkEntity) LastKick() string {
+	if len(n.kicked) < 1 {
+		return ""
+	}
+	return n.kicked[len(n.kicked)-1]
+}
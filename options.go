@@ -13,6 +13,7 @@ import (
 	"github.com/revzim/amoeba/internal/log"
 	"github.com/revzim/amoeba/internal/message"
 	"github.com/revzim/amoeba/pipeline"
+	"github.com/revzim/amoeba/ratelimit"
 	"github.com/revzim/amoeba/serialize"
 	"github.com/revzim/azdrivers"
 	"google.golang.org/grpc"
@@ -85,6 +86,90 @@ func WithMaster() Option {
 	}
 }
 
+// WithMasters sets the service addresses of all master nodes. The master nodes
+// will elect a leader and replicate the member list among them if there are
+// more than one master, and the cluster members will register to any of them
+func WithMasters(addrs ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.Masters = addrs
+	}
+}
+
+// WithElectionTimeout sets the leader election timeout of master nodes, a new
+// election will be started if a follower has not heard from the leader in it
+func WithElectionTimeout(d time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.ElectionTimeout = d
+	}
+}
+
+// WithStreamTransport sets the option to forward the messages between cluster
+// members over long-lived bidirectional streams, which keeps the messages of a
+// session in order
+func WithStreamTransport() Option {
+	return func(opt *cluster.Options) {
+		opt.StreamTransport = true
+	}
+}
+
+// WithSessionResume keeps the session of a broken connection for the grace
+// period, the client could resume the session with the token received in the
+// handshake response, the session data, group memberships and pushes are kept
+func WithSessionResume(grace time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.ResumeGrace = grace
+	}
+}
+
+// WithCompression enables the packet compression which negotiated with clients
+// in handshake, algorithms are cluster.CompressDeflate and cluster.CompressZstd,
+// the packets shorter than threshold will be sent uncompressed
+func WithCompression(threshold int, algos ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.Compression = algos
+		opt.CompressThreshold = threshold
+	}
+}
+
+// WithCompressionDict sets the shared dictionary of deflate compression, it is
+// used only if the client holds the same dictionary
+func WithCompressionDict(dict []byte) Option {
+	return func(opt *cluster.Options) {
+		opt.CompressDict = dict
+	}
+}
+
+// WithReassembly sets the reassembly limitation of the packets which larger than
+// the max packet size, limit is the max reassembly memory of each connection,
+// and the fragments of a packet should be received within timeout
+func WithReassembly(limit int, timeout time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.MaxReassemblySize = limit
+		opt.ReassemblyTimeout = timeout
+	}
+}
+
+// WithPushBatch sets the flush window of push messages from backend nodes to gate,
+// the pushes to the same gate within the window will be sent in one batch, which
+// reduces the rpc calls in high frequency pushing
+func WithPushBatch(window time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.PushBatchWindow = window
+	}
+}
+
+// WithMemberHeartbeat sets the interval of heartbeats which members send to master
+// nodes, the members missed more than maxMissed heartbeats will be evicted from
+// cluster
+func WithMemberHeartbeat(interval time.Duration, maxMissed ...int) Option {
+	return func(opt *cluster.Options) {
+		opt.MemberHeartbeat = interval
+		if len(maxMissed) > 0 {
+			opt.MaxMissedHeartbeats = maxMissed[0]
+		}
+	}
+}
+
 // WithGrpcOptions sets the grpc dial options
 func WithGrpcOptions(opts ...grpc.DialOption) Option {
 	return func(_ *cluster.Options) {
@@ -106,6 +191,15 @@ func WithHeartbeatInterval(d time.Duration) Option {
 	}
 }
 
+// WithCallTimeout sets the default deadline of `Session.Call` and `Node.Call`,
+// which will be used when the context has no deadline. It is also the deadline
+// of the client messages forwarded to the remote services
+func WithCallTimeout(d time.Duration) Option {
+	return func(_ *cluster.Options) {
+		env.CallTimeout = d
+	}
+}
+
 // WithCheckOriginFunc sets the function that check `Origin` in http headers
 func WithCheckOriginFunc(fn func(*http.Request) bool) Option {
 	return func(opt *cluster.Options) {
@@ -127,6 +221,51 @@ func WithDictionary(dict map[string]uint16) Option {
 	}
 }
 
+// WithClientVersion sets the minimum version of the client type, the clients
+// with lower version or without type will be rejected in handshake with code 501
+func WithClientVersion(clientType, minVersion string) Option {
+	return func(opt *cluster.Options) {
+		if opt.ClientVersions == nil {
+			opt.ClientVersions = map[string]string{}
+		}
+		opt.ClientVersions[clientType] = minVersion
+	}
+}
+
+// WithCapabilities sets the capabilities supported by server, the ones also
+// supported by client will be stored in the handshake info of session
+func WithCapabilities(capabilities ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.Capabilities = append(opt.Capabilities, capabilities...)
+	}
+}
+
+// WithHandshakeHandler sets the function which returns the per-session data
+// of handshake response, it is called after the handshake info stored on session
+func WithHandshakeHandler(fn cluster.HandshakeHandler) Option {
+	return func(opt *cluster.Options) {
+		opt.HandshakeHandler = fn
+	}
+}
+
+// WithAutoDictionary generates the routes dictionary from the handlers of all
+// registered components and the push routes, the codes will be recorded in
+// lockFile to keep them stable across restarts if lockFile is not empty. The
+// routes set by WithDictionary keep their codes
+func WithAutoDictionary(lockFile string) Option {
+	return func(opt *cluster.Options) {
+		opt.AutoDictionary = true
+		opt.DictionaryLock = lockFile
+	}
+}
+
+// WithPushRoutes adds the routes pushed to clients into the generated dictionary
+func WithPushRoutes(routes ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.PushRoutes = append(opt.PushRoutes, routes...)
+	}
+}
+
 func WithWSPath(path string) Option {
 	return func(_ *cluster.Options) {
 		env.WSPath = path
@@ -188,3 +327,22 @@ func WithHandshakeValidator(fn func([]byte) error) Option {
 		env.HandshakeValidator = fn
 	}
 }
+
+// WithRateLimit sets the rate limits of client messages, the messages exceeded
+// limits will be treated according to the policy of config
+func WithRateLimit(config ratelimit.Config) Option {
+	return func(opt *cluster.Options) {
+		opt.RateLimit = &config
+	}
+}
+
+// WithKeyExchange enables the X25519 key exchange in handshake, the session
+// cipher will be derived for the clients which request it, the payloads could
+// be encrypted by the pipeline stages of crypt.InstallStages, or the ones of
+// crypt.InstallSequencedStages which also reject the replayed payloads, and
+// the clients without key exchange are refused once the stages installed
+func WithKeyExchange() Option {
+	return func(opt *cluster.Options) {
+		opt.KeyExchange = true
+	}
+}
@@ -25,15 +25,42 @@ type (
 	Channel interface {
 		PushFront(h Func)
 		PushBack(h Func)
+		PushFrontNamed(name string, h Func)
+		PushBackNamed(name string, h Func)
+		Remove(name string) bool
+		Has(name string) bool
+		PushFrontConnection(name string, requires Requirement, h Func)
+		PushBackConnection(name string, requires Requirement, h Func)
+		ConnectionStages() []string
+		Requires() Requirement
 		Process(s *session.Session, msg *message.Message) error
+		ProcessNamed(s *session.Session, msg *message.Message, names ...string) ([]string, error)
+		ProcessExcept(s *session.Session, msg *message.Message, names ...string) error
+	}
+
+	// Requirement is the handshake negotiation which a connection stage
+	// depends on, the handshake without it will be refused
+	Requirement int
+
+	// stage is a function of channel, the stage without name can not be removed
+	stage struct {
+		name       string
+		fn         Func
+		connection bool        // bound to the client connection
+		requires   Requirement // handshake negotiation the stage depends on
 	}
 
 	pipelineChannel struct {
 		sync.RWMutex
-		handlers []Func
+		handlers []stage
 	}
 )
 
+const (
+	RequireCipher Requirement = 1 << iota // session cipher negotiated by key exchange
+	RequireNonce                          // connection nonce exchanged in handshake
+)
+
 func New() Pipeline {
 	return &pipeline{
 		outbound: &pipelineChannel{},
@@ -46,19 +73,122 @@ func (p *pipeline) Inbound() Channel  { return p.inbound }
 
 // PushFront push a function to the front of the pipeline
 func (p *pipelineChannel) PushFront(h Func) {
+	p.PushFrontNamed("", h)
+}
+
+// PushFront push a function to the end of the pipeline
+func (p *pipelineChannel) PushBack(h Func) {
+	p.PushBackNamed("", h)
+}
+
+// PushFrontNamed push a named function to the front of the pipeline, the
+// function with the same name will be replaced
+func (p *pipelineChannel) PushFrontNamed(name string, h Func) {
+	p.pushFront(stage{name: name, fn: h})
+}
+
+// PushBackNamed push a named function to the end of the pipeline, the
+// function with the same name will be replaced
+func (p *pipelineChannel) PushBackNamed(name string, h Func) {
+	p.pushBack(stage{name: name, fn: h})
+}
+
+// PushFrontConnection push a named function bound to the client connection to
+// the front of the pipeline. The connection stages of inbound run on the node
+// which the client connected to, before the message forwarded to the remote
+// member, e.g. decrypting with the session cipher. The handshake will be
+// refused if the negotiation in requires is not completed
+func (p *pipelineChannel) PushFrontConnection(name string, requires Requirement, h Func) {
+	p.pushFront(stage{name: name, fn: h, connection: true, requires: requires})
+}
+
+// PushBackConnection push a named function bound to the client connection to
+// the end of the pipeline, see PushFrontConnection
+func (p *pipelineChannel) PushBackConnection(name string, requires Requirement, h Func) {
+	p.pushBack(stage{name: name, fn: h, connection: true, requires: requires})
+}
+
+func (p *pipelineChannel) pushFront(st stage) {
 	p.Lock()
 	defer p.Unlock()
-	handlers := make([]Func, len(p.handlers)+1)
-	handlers[0] = h
+	p.remove(st.name)
+	handlers := make([]stage, len(p.handlers)+1)
+	handlers[0] = st
 	copy(handlers[1:], p.handlers)
 	p.handlers = handlers
 }
 
-// PushFront push a function to the end of the pipeline
-func (p *pipelineChannel) PushBack(h Func) {
+func (p *pipelineChannel) pushBack(st stage) {
+	p.Lock()
+	defer p.Unlock()
+	p.remove(st.name)
+	p.handlers = append(p.handlers, st)
+}
+
+// ConnectionStages returns the names of the functions bound to the client
+// connection in pipeline order
+func (p *pipelineChannel) ConnectionStages() []string {
+	p.RLock()
+	defer p.RUnlock()
+	var names []string
+	for _, h := range p.handlers {
+		if h.connection && h.name != "" {
+			names = append(names, h.name)
+		}
+	}
+	return names
+}
+
+// Requires returns the handshake negotiations which the connection stages
+// depend on
+func (p *pipelineChannel) Requires() Requirement {
+	p.RLock()
+	defer p.RUnlock()
+	var requires Requirement
+	for _, h := range p.handlers {
+		if h.connection {
+			requires |= h.requires
+		}
+	}
+	return requires
+}
+
+// Remove removes the function pushed with name, returns false if there is
+// no such function
+func (p *pipelineChannel) Remove(name string) bool {
 	p.Lock()
 	defer p.Unlock()
-	p.handlers = append(p.handlers, h)
+	return p.remove(name)
+}
+
+// Has reports whether the function pushed with name is in the pipeline
+func (p *pipelineChannel) Has(name string) bool {
+	p.RLock()
+	defer p.RUnlock()
+	if name == "" {
+		return false
+	}
+	for _, h := range p.handlers {
+		if h.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pipelineChannel) remove(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, h := range p.handlers {
+		if h.name == name {
+			handlers := make([]stage, 0, len(p.handlers)-1)
+			handlers = append(handlers, p.handlers[:i]...)
+			p.handlers = append(handlers, p.handlers[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
 
 // Process process message with all pipeline functions
@@ -69,10 +199,53 @@ func (p *pipelineChannel) Process(s *session.Session, msg *message.Message) erro
 		return nil
 	}
 	for _, h := range p.handlers {
-		err := h(s, msg)
+		err := h.fn(s, msg)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// ProcessNamed process message with the functions pushed with the names only,
+// returns the names of the processed functions in pipeline order
+func (p *pipelineChannel) ProcessNamed(s *session.Session, msg *message.Message, names ...string) ([]string, error) {
+	p.RLock()
+	defer p.RUnlock()
+	var processed []string
+	for _, h := range p.handlers {
+		if h.name == "" || !contains(names, h.name) {
+			continue
+		}
+		if err := h.fn(s, msg); err != nil {
+			return processed, err
+		}
+		processed = append(processed, h.name)
+	}
+	return processed, nil
+}
+
+// ProcessExcept process message with all pipeline functions except the
+// functions pushed with the names
+func (p *pipelineChannel) ProcessExcept(s *session.Session, msg *message.Message, names ...string) error {
+	p.RLock()
+	defer p.RUnlock()
+	for _, h := range p.handlers {
+		if h.name != "" && contains(names, h.name) {
+			continue
+		}
+		if err := h.fn(s, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
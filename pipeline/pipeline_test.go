@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/revzim/amoeba/session"
+)
+
+func TestNamedStage(t *testing.T) {
+	var trace string
+	stage := func(name string) Func {
+		return func(_ *session.Session, _ *Message) error {
+			trace += name
+			return nil
+		}
+	}
+
+	p := New()
+	p.Inbound().PushBack(stage("a"))
+	p.Inbound().PushBackNamed("auth", stage("b"))
+	p.Inbound().PushFrontNamed("stats", stage("c"))
+	if err := p.Inbound().Process(nil, &Message{}); err != nil {
+		t.Fatal(err)
+	}
+	if trace != "cab" {
+		t.Fatalf("expect cab, got %s", trace)
+	}
+
+	// the stage with the same name is replaced
+	trace = ""
+	p.Inbound().PushBackNamed("stats", stage("d"))
+	if !p.Inbound().Remove("auth") || p.Inbound().Remove("auth") || p.Inbound().Remove("") {
+		t.Fatal("unexpected remove result")
+	}
+	if err := p.Inbound().Process(nil, &Message{}); err != nil {
+		t.Fatal(err)
+	}
+	if trace != "ad" {
+		t.Fatalf("expect ad, got %s", trace)
+	}
+	if !p.Inbound().Has("stats") || p.Inbound().Has("auth") || p.Inbound().Has("") {
+		t.Fatal("unexpected has result")
+	}
+}
+
+func TestProcessNamed(t *testing.T) {
+	var trace string
+	stage := func(name string) Func {
+		return func(_ *session.Session, _ *Message) error {
+			trace += name
+			return nil
+		}
+	}
+
+	p := New()
+	p.Inbound().PushBack(stage("a"))
+	p.Inbound().PushBackNamed("crypt", stage("b"))
+	p.Inbound().PushBackNamed("auth", stage("c"))
+	processed, err := p.Inbound().ProcessNamed(nil, &Message{}, "crypt", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace != "b" || len(processed) != 1 || processed[0] != "crypt" {
+		t.Fatalf("unexpected process result: %s %v", trace, processed)
+	}
+
+	trace = ""
+	if err := p.Inbound().ProcessExcept(nil, &Message{}, processed...); err != nil {
+		t.Fatal(err)
+	}
+	if trace != "ac" {
+		t.Fatalf("expect ac, got %s", trace)
+	}
+}
+
+func TestConnectionStages(t *testing.T) {
+	noop := func(_ *session.Session, _ *Message) error { return nil }
+
+	p := New()
+	p.Inbound().PushBackNamed("auth", noop)
+	p.Inbound().PushFrontConnection("crypt", RequireCipher, noop)
+	p.Inbound().PushFrontConnection("sequence", RequireNonce, noop)
+	names := p.Inbound().ConnectionStages()
+	if len(names) != 2 || names[0] != "sequence" || names[1] != "crypt" {
+		t.Fatalf("unexpected connection stages: %v", names)
+	}
+	if p.Inbound().Requires() != RequireCipher|RequireNonce {
+		t.Fatalf("unexpected requirements: %v", p.Inbound().Requires())
+	}
+
+	// the stage replaced by a regular one is no longer bound to connection
+	p.Inbound().PushBackNamed("crypt", noop)
+	if p.Inbound().Requires() != RequireNonce {
+		t.Fatalf("unexpected requirements: %v", p.Inbound().Requires())
+	}
+}
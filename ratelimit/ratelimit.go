@@ -0,0 +1,268 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ratelimit limits the rate of client messages with token buckets, the
+// limits could be applied per session, per uid and per route.
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// uidSweepInterval is the interval of releasing the refilled uid buckets
+const uidSweepInterval = time.Minute
+
+// Policy decides how to treat the message which exceeded the limit
+type Policy int
+
+// Policies of the messages exceeded limit
+const (
+	Reject Policy = iota // drop the message and send an error response to request
+	Drop                 // drop the message silently
+	Kick                 // kick the session
+)
+
+type (
+	// Limit allows Rate messages per second with bursts of at most Burst
+	// messages, the limit whose Rate is not positive is unlimited
+	Limit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// Config contains the limits of client messages
+	Config struct {
+		Session Limit            // limit of each session
+		UID     Limit            // limit of the sessions bound to the same uid
+		Routes  map[string]Limit // limit of route in each session
+		Policy  Policy           // policy of the messages exceeded limit
+	}
+
+	// Violations contains the counters of the messages exceeded limits
+	Violations struct {
+		Session uint64            // messages exceeded session limit
+		UID     uint64            // messages exceeded uid limit
+		Routes  map[string]uint64 // messages exceeded route limit by route
+	}
+
+	// Bucket is a token bucket which is refilled at the rate of limit
+	Bucket struct {
+		limit  Limit
+		tokens float64
+		last   time.Time
+	}
+
+	// Limiter holds the buckets of sessions, it is safe for concurrent use
+	Limiter struct {
+		mu       sync.Mutex
+		config   Config
+		sessions map[int64]*sessionBuckets
+		uids     map[int64]*Bucket
+		swept    time.Time // last time the uid buckets swept
+
+		sessionViolations uint64
+		uidViolations     uint64
+		routeViolations   sync.Map // route => *uint64
+
+		now func() time.Time
+	}
+
+	sessionBuckets struct {
+		session  *Bucket
+		routes   map[string]*Bucket // buckets of route limits in config
+		handlers map[string]*Bucket // buckets of the limits passed to AllowRoute
+	}
+)
+
+// Unlimited reports whether the limit allows any rate
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// NewBucket returns a full bucket of the limit
+func NewBucket(limit Limit, now time.Time) *Bucket {
+	return &Bucket{limit: limit, tokens: float64(limit.burst()), last: now}
+}
+
+// Allow takes a token from bucket, returns false if there is no token
+func (b *Bucket) Allow(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Full reports whether the bucket has been refilled to burst, which means the
+// bucket is equivalent to a new one
+func (b *Bucket) Full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= float64(b.limit.burst())
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.limit.Rate
+		if burst := float64(b.limit.burst()); b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+}
+
+// burst returns the capacity of bucket, which is at least one token
+func (l Limit) burst() int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
+
+// New returns a limiter of the config
+func New(config Config) *Limiter {
+	return &Limiter{
+		config:   config,
+		sessions: map[int64]*sessionBuckets{},
+		uids:     map[int64]*Bucket{},
+		now:      time.Now,
+	}
+}
+
+// Policy returns the policy of the messages exceeded limit
+func (l *Limiter) Policy() Policy {
+	return l.config.Policy
+}
+
+// Allow reports whether the message of route sent by the session is allowed by
+// the limits of config, the uid limit is skipped if uid is zero
+func (l *Limiter) Allow(sid, uid int64, route string) bool {
+	c := &l.config
+	routeLimit := c.Routes[route]
+	if c.Session.Unlimited() && c.UID.Unlimited() && routeLimit.Unlimited() {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !c.Session.Unlimited() {
+		sb := l.bucketsOf(sid)
+		if sb.session == nil {
+			sb.session = NewBucket(c.Session, now)
+		}
+		if !sb.session.Allow(now) {
+			atomic.AddUint64(&l.sessionViolations, 1)
+			return false
+		}
+	}
+	if uid > 0 && !c.UID.Unlimited() {
+		l.sweep(now)
+		b, found := l.uids[uid]
+		if !found {
+			b = NewBucket(c.UID, now)
+			l.uids[uid] = b
+		}
+		if !b.Allow(now) {
+			atomic.AddUint64(&l.uidViolations, 1)
+			return false
+		}
+	}
+	if routeLimit.Unlimited() {
+		return true
+	}
+	return l.allowRoute(l.bucketsOf(sid).routes, route, routeLimit, now)
+}
+
+// AllowRoute reports whether the message of route sent by the session is
+// allowed by the limit, which is usually declared by the handler
+func (l *Limiter) AllowRoute(sid int64, route string, limit Limit) bool {
+	if limit.Unlimited() {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.allowRoute(l.bucketsOf(sid).handlers, route, limit, l.now())
+}
+
+func (l *Limiter) allowRoute(buckets map[string]*Bucket, route string, limit Limit, now time.Time) bool {
+	b, found := buckets[route]
+	if !found {
+		b = NewBucket(limit, now)
+		buckets[route] = b
+	}
+	if b.Allow(now) {
+		return true
+	}
+	counter, _ := l.routeViolations.LoadOrStore(route, new(uint64))
+	atomic.AddUint64(counter.(*uint64), 1)
+	return false
+}
+
+func (l *Limiter) bucketsOf(sid int64) *sessionBuckets {
+	sb, found := l.sessions[sid]
+	if !found {
+		sb = &sessionBuckets{routes: map[string]*Bucket{}, handlers: map[string]*Bucket{}}
+		l.sessions[sid] = sb
+	}
+	return sb
+}
+
+// Forget releases the buckets of the closed session, the bucket of uid will be
+// released if it has been refilled, so reconnecting does not reset the limit.
+// Otherwise it will be released by the sweep of later Allow calls.
+func (l *Limiter) Forget(sid, uid int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sessions, sid)
+	if b, found := l.uids[uid]; found && b.Full(l.now()) {
+		delete(l.uids, uid)
+	}
+}
+
+// sweep releases the uid buckets which have been refilled periodically, the
+// bucket of uid whose last session closed before refilled is released here
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < uidSweepInterval {
+		return
+	}
+	l.swept = now
+	for uid, b := range l.uids {
+		if b.Full(now) {
+			delete(l.uids, uid)
+		}
+	}
+}
+
+// Violations returns a snapshot of the violation counters
+func (l *Limiter) Violations() Violations {
+	v := Violations{
+		Session: atomic.LoadUint64(&l.sessionViolations),
+		UID:     atomic.LoadUint64(&l.uidViolations),
+		Routes:  map[string]uint64{},
+	}
+	l.routeViolations.Range(func(route, counter interface{}) bool {
+		v.Routes[route.(string)] = atomic.LoadUint64(counter.(*uint64))
+		return true
+	})
+	return v
+}
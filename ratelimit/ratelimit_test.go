@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	now := time.Now()
+	b := NewBucket(Limit{Rate: 10, Burst: 2}, now)
+	if !b.Allow(now) || !b.Allow(now) || b.Allow(now) {
+		t.Fatal("bucket should allow the burst only")
+	}
+	if b.Full(now) {
+		t.Fatal("bucket should not be full")
+	}
+	now = now.Add(100 * time.Millisecond)
+	if !b.Allow(now) || b.Allow(now) {
+		t.Fatal("bucket should be refilled one token")
+	}
+	if !b.Full(now.Add(time.Second)) {
+		t.Fatal("bucket should be refilled to burst")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := New(Config{
+		Session: Limit{Rate: 1, Burst: 3},
+		UID:     Limit{Rate: 1, Burst: 4},
+		Routes:  map[string]Limit{"Room.Chat": {Rate: 1, Burst: 1}},
+	})
+	l.now = func() time.Time { return now }
+
+	if !l.Allow(1, 100, "Room.Chat") || l.Allow(1, 100, "Room.Chat") {
+		t.Fatal("route limit should be applied")
+	}
+	if !l.Allow(1, 100, "Room.Join") || l.Allow(1, 100, "Room.Join") {
+		t.Fatal("session limit should be applied")
+	}
+	// the other session of the same uid
+	if !l.Allow(2, 100, "Room.Join") || l.Allow(2, 100, "Room.Join") {
+		t.Fatal("uid limit should be applied")
+	}
+	if !l.AllowRoute(2, "Room.Leave", Limit{Rate: 1}) || l.AllowRoute(2, "Room.Leave", Limit{Rate: 1}) {
+		t.Fatal("handler limit should be applied")
+	}
+
+	v := l.Violations()
+	if v.Session != 1 || v.UID != 1 || v.Routes["Room.Chat"] != 1 || v.Routes["Room.Leave"] != 1 {
+		t.Fatalf("unexpected violations %+v", v)
+	}
+
+	// the uid bucket is kept until it is refilled
+	l.Forget(1, 100)
+	l.Forget(2, 100)
+	if len(l.sessions) != 0 || len(l.uids) != 1 {
+		t.Fatal("session buckets should be released")
+	}
+	now = now.Add(10 * time.Second)
+	l.Forget(3, 100)
+	if len(l.uids) != 0 {
+		t.Fatal("uid bucket should be released")
+	}
+
+	// the uid bucket not refilled when forgotten is released by later sweep
+	if !l.Allow(5, 200, "Room.Join") {
+		t.Fatal("message should be allowed")
+	}
+	l.Forget(5, 200)
+	now = now.Add(uidSweepInterval)
+	if !l.Allow(6, 300, "Room.Join") {
+		t.Fatal("message should be allowed")
+	}
+	if _, found := l.uids[200]; found || len(l.uids) != 1 {
+		t.Fatal("idle uid bucket should be swept")
+	}
+}
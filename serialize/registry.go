@@ -0,0 +1,81 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package serialize
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/revzim/amoeba/serialize/cbor"
+	"github.com/revzim/amoeba/serialize/json"
+	"github.com/revzim/amoeba/serialize/msgpack"
+	"github.com/revzim/amoeba/serialize/protobuf"
+)
+
+// The members of cluster exchange the names of the serializers of services, so
+// that the remote calls are serialized like the service expects. The serializer
+// used by services of other members must be registered with the same name on
+// all members, the built-in serializers are registered by default.
+
+var (
+	muRegistry  sync.RWMutex
+	serializers = map[string]Serializer{}
+)
+
+func init() {
+	Register("protobuf", protobuf.NewSerializer())
+	Register("json", json.NewSerializer())
+	Register("msgpack", msgpack.NewSerializer())
+	Register("cbor", cbor.NewSerializer())
+}
+
+// Register makes the serializer available by the name, the serializers of the
+// same type are regarded as the same one
+func Register(name string, serializer Serializer) {
+	muRegistry.Lock()
+	defer muRegistry.Unlock()
+	serializers[name] = serializer
+}
+
+// Lookup returns the serializer registered with the name, nil will be returned
+// if not found
+func Lookup(name string) Serializer {
+	muRegistry.RLock()
+	defer muRegistry.RUnlock()
+	return serializers[name]
+}
+
+// NameOf returns the name of the serializer, an empty string will be returned
+// if the serializer is not registered
+func NameOf(serializer Serializer) string {
+	if serializer == nil {
+		return ""
+	}
+	typ := reflect.TypeOf(serializer)
+	muRegistry.RLock()
+	defer muRegistry.RUnlock()
+	for name, s := range serializers {
+		if reflect.TypeOf(s) == typ {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Copyright (c) amoeba Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package session
+
+// HandshakeInfo represents the system data of client handshake, which is
+// parsed and stored on the session by the gate which the client connected to
+type HandshakeInfo struct {
+	Protocol     int      // protocol version of client, zero for legacy clients
+	Type         string   // client type, e.g. go-client
+	Version      string   // client version
+	Capabilities []string // capabilities supported by both client and server
+}
+
+// Has reports whether the capability is supported by both client and server
+func (h *HandshakeInfo) Has(capability string) bool {
+	if h == nil {
+		return false
+	}
+	for _, c := range h.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// HandshakeInfo returns the handshake info of client, nil if the handshake has
+// not been completed or the session is not held by the gate
+func (s *Session) HandshakeInfo() *HandshakeInfo {
+	s.RLock()
+	defer s.RUnlock()
+	return s.handshake
+}
+
+// SetHandshakeInfo stores the handshake info of client, the transport values
+// of the previous connection are dropped
+func (s *Session) SetHandshakeInfo(info *HandshakeInfo) {
+	s.Lock()
+	s.handshake = info
+	s.transport = nil
+	s.Unlock()
+}
+
+// TransportValue returns the value bound to the current connection of session,
+// it is created by init if absent. Unlike the session data, the values are not
+// synchronized and restart from scratch when the client resumed the session on
+// a new connection, e.g. the sequences of encrypted messages
+func (s *Session) TransportValue(key string, init func() interface{}) interface{} {
+	s.Lock()
+	defer s.Unlock()
+	if v, found := s.transport[key]; found {
+		return v
+	}
+	if s.transport == nil {
+		s.transport = map[string]interface{}{}
+	}
+	v := init()
+	s.transport[key] = v
+	return v
+}
+
+// Cipher encrypts and decrypts the message payloads of session with the keys
+// negotiated in handshake, see crypt.KeyPair
+type Cipher interface {
+	Seal(plaintext []byte) ([]byte, error)
+	Open(ciphertext []byte) ([]byte, error)
+}
+
+// Cipher returns the cipher negotiated in handshake, nil if the client does
+// not exchange keys or the session is not held by the gate
+func (s *Session) Cipher() Cipher {
+	s.RLock()
+	defer s.RUnlock()
+	return s.cipher
+}
+
+// SetCipher stores the cipher negotiated in handshake, it is replaced by the
+// new handshake after the session resumed on another connection
+func (s *Session) SetCipher(c Cipher) {
+	s.Lock()
+	s.cipher = c
+	s.Unlock()
+}
+
+// Nonce returns the random value exchanged in handshake, which is unique for
+// each connection of session, nil if the client does not send nonce
+func (s *Session) Nonce() []byte {
+	s.RLock()
+	defer s.RUnlock()
+	return s.nonce
+}
+
+// SetNonce stores the random value exchanged in handshake, the messages bound
+// to the nonce are not valid on other connections, e.g. the sequenced messages
+func (s *Session) SetNonce(nonce []byte) {
+	s.Lock()
+	s.nonce = nonce
+	s.Unlock()
+}
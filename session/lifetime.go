@@ -1,5 +1,7 @@
 package session
 
+import "sync"
+
 type (
 	// LifetimeHandler represents a callback
 	// that will be called when a session close or
@@ -7,6 +9,7 @@ type (
 	LifetimeHandler func(*Session)
 
 	lifetime struct {
+		sync.RWMutex
 		// callbacks that emitted on session closed
 		onClosed []LifetimeHandler
 	}
@@ -17,15 +20,21 @@ var Lifetime = &lifetime{}
 // OnClosed set the Callback which will be called
 // when session is closed Waring: session has closed.
 func (lt *lifetime) OnClosed(h LifetimeHandler) {
+	lt.Lock()
+	defer lt.Unlock()
 	lt.onClosed = append(lt.onClosed, h)
 }
 
 func (lt *lifetime) Close(s *Session) {
-	if len(lt.onClosed) < 1 {
+	s.cancel()
+	lt.RLock()
+	onClosed := lt.onClosed
+	lt.RUnlock()
+	if len(onClosed) < 1 {
 		return
 	}
 
-	for _, h := range lt.onClosed {
+	for _, h := range onClosed {
 		h(s)
 	}
 }
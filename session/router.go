@@ -44,3 +44,16 @@ func (r *Router) Find(service string) (string, bool) {
 	}
 	return v.(string), true
 }
+
+// Unbind removes the address bound to remote service
+func (r *Router) Unbind(service string) {
+	r.routes.Delete(service)
+}
+
+// Range calls f sequentially for each service and bound address, the range
+// stops if f returns false
+func (r *Router) Range(f func(service, address string) bool) {
+	r.routes.Range(func(k, v interface{}) bool {
+		return f(k.(string), v.(string))
+	})
+}
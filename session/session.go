@@ -21,6 +21,7 @@
 package session
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -37,20 +38,71 @@ import (
 type NetworkEntity interface {
 	Push(route string, v interface{}) error
 	RPC(route string, v interface{}) error
+	Call(ctx context.Context, route string, v interface{}, reply interface{}) error
 	LastMid() uint64
 	Response(v interface{}) error
 	ResponseMid(mid uint64, v interface{}) error
 	Close() error
+	Kick(reason string) error
 	RemoteAddr() net.Addr
 }
 
+// Synchronizer is implemented by the network entities which share the session
+// state with other nodes, Sync will be called with the delta after the state
+// changed by Bind, Set, Remove, Restore or Clear. It should not block the caller
+type Synchronizer interface {
+	Sync(uid int64, delta Delta)
+}
+
+// Delta is a change of session state, Values holds the keys set and Removed
+// holds the keys deleted, the whole state is replaced by Values if Reset. The
+// uid synchronized with delta is applied only if UIDChanged, which is set by
+// Bind, so the stale uid of other nodes will not overwrite the bound one
+type Delta struct {
+	Values     map[string]interface{}
+	Removed    []string
+	Reset      bool
+	UIDChanged bool
+}
+
+// Merge applies the newer delta over current one, so they could be applied
+// as one delta
+func (d *Delta) Merge(newer Delta) {
+	d.UIDChanged = d.UIDChanged || newer.UIDChanged
+	if newer.Reset {
+		d.Values, d.Removed, d.Reset = nil, nil, true
+	}
+	for k, v := range newer.Values {
+		if d.Values == nil {
+			d.Values = map[string]interface{}{}
+		}
+		d.Values[k] = v
+		d.Removed = removeKey(d.Removed, k)
+	}
+	for _, k := range newer.Removed {
+		delete(d.Values, k)
+		if !d.Reset {
+			d.Removed = append(removeKey(d.Removed, k), k)
+		}
+	}
+}
+
+func removeKey(keys []string, key string) []string {
+	for i, k := range keys {
+		if k == key {
+			return append(keys[:i:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
+
 type (
 	// Session represents a client session which could storage temp data during low-level
 	// keep connected, all data will be released when the low-level connection was broken.
 	// Session instance related to the client will be passed to Handler method as the first
 	// parameter.
 	Session struct {
-		sync.RWMutex                        // protect data
+		sync.RWMutex                        // protect data and entity
 		id           int64                  // session global unique id
 		uid          int64                  // binding user id
 		uuid         string                 //
@@ -58,6 +110,12 @@ type (
 		entity       NetworkEntity          // low-level network entity
 		data         map[string]interface{} // session data store
 		router       *Router
+		handshake    *HandshakeInfo         // handshake info of client
+		cipher       Cipher                 // cipher negotiated in handshake
+		nonce        []byte                 // nonce of current connection
+		transport    map[string]interface{} // state of current connection
+		ctx          context.Context        // cancelled when the session closed
+		cancel       context.CancelFunc     // cancels ctx
 	}
 )
 
@@ -79,20 +137,39 @@ var (
 // New returns a new session instance
 // a NetworkEntity is a low-level network instance
 func New(entity NetworkEntity) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Session{
 		id:       service.Connections.SessionID(),
 		entity:   entity,
 		data:     make(map[string]interface{}),
 		lastTime: time.Now().Unix(),
 		router:   newRouter(),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
 }
 
 // NetworkEntity returns the low-level network agent object
 func (s *Session) NetworkEntity() NetworkEntity {
+	s.RLock()
+	defer s.RUnlock()
 	return s.entity
 }
 
+// Attach replaces the low-level network entity, it is used to re-attach the
+// session to a new connection when the client resumed the session
+func (s *Session) Attach(entity NetworkEntity) {
+	s.Lock()
+	s.entity = entity
+	s.Unlock()
+}
+
+// Context returns the context of session, which will be cancelled when the
+// session closed, the contexts passed to handlers are derived from it
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
 // NetworkEntity returns the service router
 func (s *Session) Router() *Router {
 	return s.router
@@ -100,23 +177,30 @@ func (s *Session) Router() *Router {
 
 // RPC sends message to remote server
 func (s *Session) RPC(route string, v interface{}) error {
-	return s.entity.RPC(route, v)
+	return s.NetworkEntity().RPC(route, v)
+}
+
+// Call sends a request to remote server and waits for the reply, the reply
+// payload will be unmarshaled into reply. A default deadline will be applied
+// if ctx does not carry one
+func (s *Session) Call(ctx context.Context, route string, v interface{}, reply interface{}) error {
+	return s.NetworkEntity().Call(ctx, route, v, reply)
 }
 
 // Push message to client
 func (s *Session) Push(route string, v interface{}) error {
-	return s.entity.Push(route, v)
+	return s.NetworkEntity().Push(route, v)
 }
 
 // Response message to client
 func (s *Session) Response(v interface{}) error {
-	return s.entity.Response(v)
+	return s.NetworkEntity().Response(v)
 }
 
 // ResponseMID responses message to client, mid is
 // request message ID
 func (s *Session) ResponseMID(mid uint64, v interface{}) error {
-	return s.entity.ResponseMid(mid, v)
+	return s.NetworkEntity().ResponseMid(mid, v)
 }
 
 // ID returns the session id
@@ -131,7 +215,7 @@ func (s *Session) UID() int64 {
 
 // LastMid returns the last message id
 func (s *Session) LastMid() uint64 {
-	return s.entity.LastMid()
+	return s.NetworkEntity().LastMid()
 }
 
 // Bind bind UID to current session
@@ -143,6 +227,7 @@ func (s *Session) Bind(uid int64) error {
 	atomic.StoreInt64(&s.uid, uid)
 	// s.uuid = uuid.New().String()
 	s.initUUID("")
+	s.sync(Delta{UIDChanged: true})
 	return nil
 }
 
@@ -168,28 +253,37 @@ func (s *Session) ShortUUID() string {
 // Close terminate current session, session related data will not be released,
 // all related data should be Clear explicitly in Session closed callback
 func (s *Session) Close() {
-	s.entity.Close()
+	s.NetworkEntity().Close()
+}
+
+// Kick sends a kick packet with the reason to client and closes the session
+// after the pending messages have been sent, so the client could distinguish
+// the kick from a broken connection
+func (s *Session) Kick(reason string) error {
+	return s.NetworkEntity().Kick(reason)
 }
 
 // RemoteAddr returns the remote network address.
 func (s *Session) RemoteAddr() net.Addr {
-	return s.entity.RemoteAddr()
+	return s.NetworkEntity().RemoteAddr()
 }
 
 // Remove delete data associated with the key from session storage
 func (s *Session) Remove(key string) {
 	s.Lock()
-	defer s.Unlock()
-
 	delete(s.data, key)
+	s.Unlock()
+
+	s.sync(Delta{Removed: []string{key}})
 }
 
 // Set associates value with the key in session storage
 func (s *Session) Set(key string, value interface{}) {
 	s.Lock()
-	defer s.Unlock()
-
 	s.data[key] = value
+	s.Unlock()
+
+	s.sync(Delta{Values: map[string]interface{}{key: value}})
 }
 
 // HasKey decides whether a key has associated value
@@ -430,27 +524,66 @@ func (s *Session) Value(key string) interface{} {
 	return s.data[key]
 }
 
-// State returns all session state
+// State returns a copy of all session state
 func (s *Session) State() map[string]interface{} {
 	s.RLock()
 	defer s.RUnlock()
 
-	return s.data
+	state := make(map[string]interface{}, len(s.data))
+	for k, v := range s.data {
+		state[k] = v
+	}
+	return state
 }
 
 // Restore session state after reconnect
 func (s *Session) Restore(data map[string]interface{}) {
 	s.Lock()
-	defer s.Unlock()
-
 	s.data = data
+	s.Unlock()
+
+	s.sync(Delta{Values: s.State(), Reset: true})
 }
 
-// Clear releases all data related to current session
-func (s *Session) Clear() {
+// Apply merges the uid and state delta synchronized from other node, the keys
+// not included in delta are kept unless the delta resets the whole state, and
+// the uid is kept unless the delta changes it. The Synchronizer will not be
+// notified
+func (s *Session) Apply(uid int64, delta Delta) {
 	s.Lock()
 	defer s.Unlock()
 
-	s.uid = 0
+	if delta.UIDChanged {
+		atomic.StoreInt64(&s.uid, uid)
+		if uid > 0 {
+			s.initUUID("")
+		}
+	}
+	if delta.Reset || s.data == nil {
+		s.data = map[string]interface{}{}
+	}
+	for k, v := range delta.Values {
+		s.data[k] = v
+	}
+	for _, k := range delta.Removed {
+		delete(s.data, k)
+	}
+}
+
+// sync notifies the Synchronizer that the state has been changed
+func (s *Session) sync(delta Delta) {
+	if syncer, ok := s.NetworkEntity().(Synchronizer); ok {
+		syncer.Sync(s.UID(), delta)
+	}
+}
+
+// Clear releases all data related to current session, the other nodes holding
+// the session are notified to reset their state
+func (s *Session) Clear() {
+	s.Lock()
+	atomic.StoreInt64(&s.uid, 0)
 	s.data = map[string]interface{}{}
+	s.Unlock()
+
+	s.sync(Delta{Reset: true, UIDChanged: true})
 }
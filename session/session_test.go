@@ -173,3 +173,70 @@ func TestSession_Restore(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestRouter_Unbind(t *testing.T) {
+	s := New(nil)
+	s.Router().Bind("Game", "127.0.0.1:4450")
+	s.Router().Bind("Chat", "127.0.0.1:4451")
+
+	var count int
+	s.Router().Range(func(service, address string) bool {
+		count++
+		return true
+	})
+	if count != 2 {
+		t.Fail()
+	}
+
+	s.Router().Unbind("Game")
+	if _, found := s.Router().Find("Game"); found {
+		t.Fail()
+	}
+	if addr, _ := s.Router().Find("Chat"); addr != "127.0.0.1:4451" {
+		t.Fail()
+	}
+}
+
+func TestSession_Context(t *testing.T) {
+	s := New(nil)
+	if err := s.Context().Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	Lifetime.Close(s)
+	select {
+	case <-s.Context().Done():
+	default:
+		t.Fatal("context should be cancelled after session closed")
+	}
+}
+
+func TestSession_Apply(t *testing.T) {
+	s := New(nil)
+	s.Set("gate", "kept")
+	s.Set("name", "amoeba")
+
+	d := Delta{}
+	d.Merge(Delta{Values: map[string]interface{}{"name": "revzim", "level": 1}})
+	d.Merge(Delta{Removed: []string{"level"}})
+	s.Apply(10086, d)
+	if s.UID() != 0 {
+		t.Fatalf("uid should be kept, got %d", s.UID())
+	}
+	d.Merge(Delta{UIDChanged: true})
+	s.Apply(10086, d)
+	if s.UID() != 10086 || s.String("gate") != "kept" || s.String("name") != "revzim" || s.HasKey("level") {
+		t.Fatalf("unexpected state: %v", s.State())
+	}
+
+	d.Merge(Delta{Values: map[string]interface{}{"level": 2}, Reset: true})
+	s.Apply(10086, d)
+	if len(s.State()) != 1 || s.Int("level") != 2 {
+		t.Fatalf("unexpected state: %v", s.State())
+	}
+
+	// the stale uid sent with the delta which does not change uid is ignored
+	s.Apply(0, Delta{Values: map[string]interface{}{"level": 3}})
+	if s.UID() != 10086 || s.Int("level") != 3 {
+		t.Fatalf("unexpected uid %d or state %v", s.UID(), s.State())
+	}
+}